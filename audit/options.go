@@ -0,0 +1,25 @@
+package audit
+
+type LoggerOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	state State
+}
+
+// WithState continues the chain after state, as returned by Verify on the existing log or
+// by Logger.State before a restart. Default is the start of a new chain.
+func WithState(state State) LoggerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.state = state
+	})
+}
+
+func newDefaultConfig() config {
+	return config{}
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/tidwall/gjson"
+)
+
+// VerifyError reports the first record breaking the chain.
+type VerifyError struct {
+	// Line is the 1-based line number of the record.
+	Line   int
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit: line %d: %s", e.Line, e.Reason)
+}
+
+// Verify checks the chain of the records read from r, starting at from, and returns the
+// state after the last record. Pass the zero State for a log starting a chain, or the
+// state of the previous log when the chain continues across files.
+func Verify(r io.Reader, key []byte, from State) (State, error) {
+	h := hmac.New(sha256.New, key)
+	state := from
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		record := sc.Bytes()
+		if len(record) == 0 {
+			continue
+		}
+		fail := func(reason string) (State, error) {
+			return state, &VerifyError{Line: line, Reason: reason}
+		}
+		idx := bytes.LastIndex(record, []byte(`,"`+FieldMAC+`":"`))
+		if idx < 0 || !bytes.HasSuffix(record, []byte(`"}`)) {
+			return fail("missing mac")
+		}
+		mac := string(record[idx+len(FieldMAC)+5 : len(record)-2])
+		signed := append(record[:idx:idx], '}')
+		if !hmac.Equal([]byte(sign(h, signed)), []byte(mac)) {
+			return fail("mac mismatch")
+		}
+		fields := gjson.ParseBytes(signed)
+		if seq := fields.Get(FieldSeq).Uint(); seq != state.Seq+1 {
+			return fail(fmt.Sprintf("sequence %d, expected %d", seq, state.Seq+1))
+		}
+		if prev := fields.Get(FieldPrev).String(); prev != state.MAC {
+			return fail("broken chain")
+		}
+		state = State{Seq: state.Seq + 1, MAC: mac}
+	}
+	return state, sc.Err()
+}
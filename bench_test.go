@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// formats are the encodings of New, compared by the benchmarks and tests of the package
+var formats = []struct {
+	name   string
+	format Format
+}{
+	{"JSON", JSON},
+	{"Console", Console},
+	{"Logfmt", Logfmt},
+	{"CBOR", CBOR},
+}
+
+// tenFields adds the ten fields of the standard benchmark event to e
+func tenFields(e *zerolog.Event) *zerolog.Event {
+	return e.
+		Str("string", "value").
+		Int("int", 42).
+		Int64("int64", 1<<40).
+		Float64("float", 3.14).
+		Bool("bool", true).
+		Dur("duration", 150*time.Millisecond).
+		Time("at", time.Date(2024, 7, 22, 16, 17, 16, 0, time.UTC)).
+		Strs("strings", []string{"a", "b"}).
+		Ints("ints", []int{1, 2, 3}).
+		AnErr("cause", errBoom)
+}
+
+var errBoom = errors.New("boom")
+
+func BenchmarkFormats(b *testing.B) {
+	for _, f := range formats {
+		l := New(WithOutput(io.Discard), WithFormat(f.format), WithNoColor())
+		b.Run(f.name+"/TenFields", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tenFields(l.Info()).Msg("benchmark event")
+			}
+		})
+		b.Run(f.name+"/Disabled", func(b *testing.B) {
+			l := l.Level(zerolog.WarnLevel)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tenFields(l.Info()).Msg("benchmark event")
+			}
+		})
+		b.Run(f.name+"/WithContext", func(b *testing.B) {
+			l := l.With().Str("service", "bench").Int("worker", 7).Logger()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info().Int("i", i).Msg("benchmark event")
+			}
+		})
+		b.Run(f.name+"/Msgf", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info().Msgf("benchmark event %d of %s", i, "msgf")
+			}
+		})
+	}
+}
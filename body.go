@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// Suffixes of the fields added by Body and BodyHeadTail.
+const (
+	BodySizeSuffix      = "_size"
+	BodyTruncatedSuffix = "_truncated"
+	BodyTailSuffix      = "_tail"
+)
+
+// bodyFields is a captured payload
+type bodyFields struct {
+	key       string
+	head      []byte
+	tail      []byte
+	size      int64
+	truncated bool
+}
+
+func (b bodyFields) MarshalZerologObject(e *zerolog.Event) {
+	e.Bytes(b.key, b.head)
+	e.Int64(b.key+BodySizeSuffix, b.size)
+	if b.truncated {
+		e.Bool(b.key+BodyTruncatedSuffix, true)
+		if len(b.tail) > 0 {
+			e.Bytes(b.key+BodyTailSuffix, b.tail)
+		}
+	}
+}
+
+// Body reads r to the end and returns the fields key with its first limit bytes and
+// key_size with its size in bytes, plus key_truncated=true when it is longer than limit.
+// Add them with Event.EmbedObject or Context.EmbedObject:
+//
+//	logger.Info().EmbedObject(logger.Body("payload", r, 1024)).Msg("received")
+//
+// r is consumed, so pass a copy of bodies still to be read.
+func Body(key string, r io.Reader, limit int) zerolog.LogObjectMarshaler {
+	return BodyHeadTail(key, r, limit, 0)
+}
+
+// BodyHeadTail is Body also keeping the last tail bytes of truncated payloads, in
+// key_tail, e.g. for the closing part of an error response.
+func BodyHeadTail(key string, r io.Reader, head, tail int) zerolog.LogObjectMarshaler {
+	if head < 0 {
+		head = 0
+	}
+	if tail < 0 {
+		tail = 0
+	}
+	b := bodyFields{key: key}
+	if r == nil {
+		return b
+	}
+	b.head, _ = io.ReadAll(io.LimitReader(r, int64(head)))
+	b.size = int64(len(b.head))
+
+	t := &tailWriter{max: tail}
+	n, _ := io.Copy(t, r)
+	b.size += n
+	b.truncated = n > 0
+	b.tail = t.bytes()
+	return b
+}
+
+// tailWriter keeps the last max bytes written to it
+type tailWriter struct {
+	max int
+	buf []byte
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	if t.max == 0 {
+		return len(p), nil
+	}
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > 2*t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailWriter) bytes() []byte {
+	if len(t.buf) > t.max {
+		return t.buf[len(t.buf)-t.max:]
+	}
+	return t.buf
+}
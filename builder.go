@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/XiBao/logger/writer/cbor"
+	"github.com/XiBao/logger/writer/frame"
+	"github.com/XiBao/logger/writer/logfmt"
+	"github.com/rs/zerolog"
+)
+
+// New creates a logger writing timestamped events in the configured format.
+// Use SetLogger to make it the global logger.
+func New(opts ...Option) zerolog.Logger {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	l := zerolog.New(cfg.writer()).Level(cfg.minLevel).With().Timestamp().Logger()
+	if cfg.source {
+		if cfg.caller == nil {
+			cfg.caller = DefaultCallerFormat
+		}
+		l = l.Hook(sourceHook{format: cfg.caller, function: true})
+	}
+	if cfg.gid {
+		l = l.Hook(goroutineHook{})
+	}
+	return l
+}
+
+func (c config) writer() io.Writer {
+	if c.framed && c.format != CBOR {
+		// frame the formatted lines
+		c.output = frame.New(c.output)
+	}
+	if c.format == Console {
+		theme := c.theme
+		if c.icons {
+			theme.Icons = LevelIcons
+		}
+		return theme.Writer(c.output, c.noColor)
+	}
+	var w io.Writer = c.output
+	switch c.format {
+	case Logfmt:
+		w = logfmt.New(w)
+	case CBOR:
+		if binaryLog {
+			return w
+		}
+		w = cbor.New(w)
+	}
+	if renames := c.names.renames(); len(renames) > 0 {
+		w = &renameWriter{next: w, renames: renames}
+	}
+	return w
+}
+
+// binaryLog reports whether zerolog was built with the binary_log tag and encodes CBOR itself
+var binaryLog = func() bool {
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	l.Log().Send()
+	return buf.Len() > 0 && buf.Bytes()[0] != '{'
+}()
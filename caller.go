@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"go/build"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerFormat formats the source location of an event, like zerolog.CallerMarshalFunc but
+// for the loggers of this package only.
+type CallerFormat func(pc uintptr, file string, line int) string
+
+// DefaultCallerFormat is the caller format of the global logger and of New, the file and its
+// directory, e.g. "logger/log.go:42".
+var DefaultCallerFormat = ShortCaller(2)
+
+// ShortCaller keeps the last depth elements of the file path, e.g. "log.go:42" for 1 and
+// "logger/log.go:42" for 2. A depth below 1 keeps the whole path.
+func ShortCaller(depth int) CallerFormat {
+	if depth < 1 {
+		return FullCaller
+	}
+	return func(_ uintptr, file string, line int) string {
+		i := len(file)
+		for n := 0; n < depth && i > 0; n++ {
+			i = strings.LastIndexByte(file[:i], '/')
+			if i < 0 {
+				break
+			}
+		}
+		return file[i+1:] + ":" + strconv.Itoa(line)
+	}
+}
+
+// FullCaller writes the file path as recorded by the compiler.
+func FullCaller(_ uintptr, file string, line int) string {
+	return file + ":" + strconv.Itoa(line)
+}
+
+// TrimGOPATH writes the file path relative to the module cache, GOPATH or GOROOT source
+// directory it lives in, e.g. "github.com/XiBao/logger@v1.2.0/log.go:42". Files outside of
+// them are written in full.
+func TrimGOPATH(_ uintptr, file string, line int) string {
+	for _, prefix := range srcPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			file = file[len(prefix):]
+			break
+		}
+	}
+	return file + ":" + strconv.Itoa(line)
+}
+
+// srcPrefixes are the directories TrimGOPATH trims, module cache first
+var srcPrefixes = func() []string {
+	var prefixes []string
+	for _, dir := range filepath.SplitList(build.Default.GOPATH) {
+		dir = filepath.ToSlash(dir)
+		prefixes = append(prefixes, path.Join(dir, "pkg/mod")+"/", path.Join(dir, "src")+"/")
+	}
+	if root := runtime.GOROOT(); root != "" {
+		prefixes = append(prefixes, path.Join(filepath.ToSlash(root), "src")+"/")
+	}
+	return prefixes
+}()
@@ -0,0 +1,52 @@
+// Command auditverify checks the chain of audit logs written by package audit.
+//
+// Usage:
+//
+//	AUDIT_KEY=... auditverify audit.log.1 audit.log
+//
+// Files are verified in order as one chain. The HMAC key is read from AUDIT_KEY.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/XiBao/logger/audit"
+)
+
+func main() {
+	key := os.Getenv("AUDIT_KEY")
+	if key == "" || len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: AUDIT_KEY=... auditverify FILE...")
+		os.Exit(2)
+	}
+	var state audit.State
+	for _, name := range os.Args[1:] {
+		var err error
+		if state, err = verify(name, []byte(key), state); err != nil {
+			var verr *audit.VerifyError
+			if errors.As(err, &verr) {
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", name, verr.Line, verr.Reason)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			}
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("ok: %d records, last mac %s\n", state.Seq, state.MAC)
+}
+
+func verify(name string, key []byte, from audit.State) (audit.State, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return from, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return audit.Verify(r, key, from)
+}
@@ -0,0 +1,209 @@
+// Command logship replays JSON or CBOR log files through one of the remote writers of this
+// package, so backfilled logs get the same mapping as live ones.
+//
+// Usage:
+//
+//	logship -to loki -url http://loki:3100 [-label key=value]... [-label-field name]... FILE...
+//	logship -to elastic -url http://es:9200 -index 'logs-{2006.01.02}' FILE...
+//	SENTRY_DSN=... logship -to sentry [-level warn] FILE...
+//
+// Lines are read from the files in order, or from stdin for "-". The writers take the
+// event time from the time field of each line. logship waits for the writer to deliver
+// every -flush lines, so large files are not dropped by the buffer bounds of the writer,
+// and exits with status 1 if any line could not be delivered.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/XiBao/logger/writer/cbor"
+	"github.com/XiBao/logger/writer/elastic"
+	"github.com/XiBao/logger/writer/loki"
+	sentrywriter "github.com/XiBao/logger/writer/sentry"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// flusher is implemented by the batching writers
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+var failures atomic.Int64
+
+func main() {
+	var (
+		to          = flag.String("to", "", "destination: loki, elastic or sentry")
+		url         = flag.String("url", "", "URL of Loki or Elasticsearch")
+		index       = flag.String("index", "logs", "Elasticsearch index name template")
+		tenant      = flag.String("tenant", "", "Loki tenant")
+		level       = flag.String("level", "error", "minimum level sent to Sentry as events")
+		useCBOR     = flag.Bool("cbor", false, "read CBOR encoded logs")
+		flushEvery  = flag.Int("flush", 1000, "lines between waits for delivery")
+		timeout     = flag.Duration("timeout", time.Minute, "maximum wait for delivery")
+		labels      listFlag
+		labelFields listFlag
+	)
+	flag.Var(&labels, "label", "static Loki `key=value` label, may be repeated")
+	flag.Var(&labelFields, "label-field", "field used as Loki label, may be repeated")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	w, err := open(*to, *url, *index, *tenant, *level, labels, labelFields)
+	if err != nil {
+		fail(err)
+	}
+
+	var lines int
+	flush := func() {
+		f, ok := w.(flusher)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if err := f.Flush(ctx); err != nil {
+			report(err)
+		}
+	}
+	for _, name := range flag.Args() {
+		err := each(name, *useCBOR, func(line []byte) {
+			if _, err := w.Write(line); err != nil {
+				report(err)
+			}
+			if lines++; *flushEvery > 0 && lines%*flushEvery == 0 {
+				flush()
+			}
+		})
+		if err != nil {
+			w.Close()
+			fail(fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	flush()
+	if err := w.Close(); err != nil {
+		report(err)
+	}
+	fmt.Fprintf(os.Stderr, "logship: %d lines, %d errors\n", lines, failures.Load())
+	if failures.Load() > 0 {
+		os.Exit(1)
+	}
+}
+
+// open creates the writer of the destination
+func open(to, url, index, tenant, level string, labels, labelFields []string) (io.WriteCloser, error) {
+	switch to {
+	case "loki":
+		static := make(map[string]string, len(labels))
+		for _, kv := range labels {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid label %q", kv)
+			}
+			static[k] = v
+		}
+		opts := []loki.WriterOption{
+			loki.WithLabels(static),
+			loki.WithLabelFields(labelFields...),
+			loki.WithErrorHandler(report),
+		}
+		if tenant != "" {
+			opts = append(opts, loki.WithTenant(tenant))
+		}
+		return loki.New(url, opts...)
+	case "elastic":
+		return elastic.New(url, index,
+			elastic.WithBackpressure(),
+			elastic.WithErrorHandler(report))
+	case "sentry":
+		min, err := zerolog.ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		var levels []zerolog.Level
+		for l := min; l <= zerolog.PanicLevel; l++ {
+			levels = append(levels, l)
+		}
+		return sentrywriter.New(
+			sentrywriter.WithClientOptions(sentry.ClientOptions{Dsn: os.Getenv("SENTRY_DSN")}),
+			sentrywriter.WithLevels(levels...),
+			sentrywriter.WithBreadcrumbs())
+	}
+	return nil, fmt.Errorf("unknown destination %q", to)
+}
+
+// each calls fn with every line of the file, decoding CBOR to JSON lines if useCBOR is set
+func each(name string, useCBOR bool, fn func(line []byte)) error {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	if useCBOR {
+		dec := cbor.NewDecoder(r)
+		for {
+			line, err := dec.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fn(line)
+		}
+	}
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			fn(line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func report(err error) {
+	failures.Add(1)
+	fmt.Fprintf(os.Stderr, "logship: %v\n", err)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "logship: %v\n", err)
+	os.Exit(1)
+}
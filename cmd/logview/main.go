@@ -0,0 +1,160 @@
+// Command logview pretty-prints JSON or CBOR logs written by this package.
+//
+// Usage:
+//
+//	logview [-level warn] [-field key=value]... [-cbor] [-f] [-no-color] [FILE]
+//
+// Lines are read from FILE, or stdin when FILE is missing or "-", and printed in the
+// Console format with the error stack one frame per line. Lines below -level are
+// skipped; lines without a level are kept. Each -field keeps only the lines where the
+// field, addressed with dots for nested fields, has the given value. -f keeps reading at
+// the end of FILE, like tail -f. Lines which are not JSON are printed as they are.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/writer/cbor"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+type fieldFilters []string
+
+func (f *fieldFilters) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fieldFilters) Set(v string) error {
+	if !strings.Contains(v, "=") {
+		return errors.New("expected key=value")
+	}
+	*f = append(*f, v)
+	return nil
+}
+
+type filter struct {
+	level  zerolog.Level
+	fields [][2]string
+}
+
+// match reports whether the JSON line passes the level and field filters
+func (f *filter) match(line []byte) bool {
+	if lvl := gjson.GetBytes(line, zerolog.LevelFieldName); lvl.Exists() {
+		if level, err := zerolog.ParseLevel(lvl.String()); err == nil && level != zerolog.NoLevel && level < f.level {
+			return false
+		}
+	}
+	for _, kv := range f.fields {
+		if v := gjson.GetBytes(line, kv[0]); !v.Exists() || v.String() != kv[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func main() {
+	var (
+		level   = flag.String("level", "trace", "minimum level to print")
+		useCBOR = flag.Bool("cbor", false, "read CBOR encoded logs")
+		follow  = flag.Bool("f", false, "keep reading at the end of the file")
+		noColor = flag.Bool("no-color", false, "disable colors")
+		fields  fieldFilters
+	)
+	flag.Var(&fields, "field", "print only lines where `key=value`, may be repeated")
+	flag.Parse()
+
+	f := &filter{}
+	var err error
+	if f.level, err = zerolog.ParseLevel(*level); err != nil {
+		fail(err)
+	}
+	for _, kv := range fields {
+		k, v, _ := strings.Cut(kv, "=")
+		f.fields = append(f.fields, [2]string{k, v})
+	}
+	if *follow && *useCBOR {
+		fail(errors.New("-f is not supported with -cbor"))
+	}
+
+	var r io.Reader = os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	w := logger.NewConsoleWriter(out, *noColor)
+	show := func(line []byte) {
+		if !gjson.ValidBytes(line) {
+			out.Write(line)
+			out.WriteByte('\n')
+			return
+		}
+		if !f.match(line) {
+			return
+		}
+		if _, err := w.Write(line); err != nil {
+			out.Write(line)
+			out.WriteByte('\n')
+		}
+	}
+
+	if *useCBOR {
+		dec := cbor.NewDecoder(r)
+		for {
+			line, err := dec.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				out.Flush()
+				fail(err)
+			}
+			show(bytes.TrimRight(line, "\n"))
+		}
+	}
+
+	br := bufio.NewReader(r)
+	var partial []byte
+	for {
+		chunk, err := br.ReadBytes('\n')
+		partial = append(partial, chunk...)
+		if err == nil {
+			show(bytes.TrimRight(partial, "\r\n"))
+			partial = partial[:0]
+			continue
+		}
+		if err != io.EOF {
+			out.Flush()
+			fail(err)
+		}
+		if !*follow {
+			if len(partial) > 0 {
+				show(partial)
+			}
+			return
+		}
+		out.Flush()
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "logview: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,6 @@
+package common
+
+// FieldCapture is the reserved field requesting that an event is captured to Sentry
+// whatever its level. It is set by the CaptureErr helper of the Sentry hook and honoured by
+// the Sentry hook and writer.
+const FieldCapture = "sentry.capture"
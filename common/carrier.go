@@ -0,0 +1,207 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// carrierSlots bounds the events marked by a hook but not yet seen by its writer. A mark
+// overwritten by a newer one is handed to the hook without its context.
+const carrierSlots = 1024
+
+// Carrier pairs a zerolog hook with a writer, so hooks can process the fully encoded log
+// line together with the event context without reading zerolog internals. The hook marks
+// events with a reference field and the writer created by Carrier.Writer strips the marker
+// before forwarding the line and handing it to the hook.
+//
+// Events are only marked once a writer is created, so a hook used without its writer does
+// not leak the reference field into the log lines. A nil Carrier marks nothing.
+type Carrier struct {
+	field    string
+	attached atomic.Bool
+	seq      atomic.Uint64
+	mu       sync.Mutex
+	pending  [carrierSlots]Marked
+}
+
+// refFields are the reference fields of all carriers, stripped from the log lines handed
+// to the hooks, so a line marked by several hooks does not carry the marks of the others.
+var refFields struct {
+	sync.RWMutex
+	names []string
+}
+
+// registerRef adds field to refFields
+func registerRef(field string) {
+	refFields.Lock()
+	defer refFields.Unlock()
+	for _, name := range refFields.names {
+		if name == field {
+			return
+		}
+	}
+	refFields.names = append(refFields.names, field)
+}
+
+// Marked is an event marked by the hook side of a Carrier.
+type Marked struct {
+	id    uint64
+	Ctx   context.Context
+	Level zerolog.Level
+}
+
+// NewCarrier returns a Carrier marking events with field.
+func NewCarrier(field string) *Carrier {
+	registerRef(field)
+	return &Carrier{field: field}
+}
+
+// Mark remembers the context and level of event and adds the reference field to it.
+// It does nothing until a writer of the Carrier is created.
+func (c *Carrier) Mark(event *zerolog.Event, level zerolog.Level) {
+	if !c.Attached() {
+		return
+	}
+	id := c.seq.Add(1)
+	ctx := event.GetCtx()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.mu.Lock()
+	c.pending[id%carrierSlots] = Marked{id: id, Ctx: ctx, Level: level}
+	c.mu.Unlock()
+	event.Uint64(c.field, id)
+}
+
+// Attached reports whether a writer of the Carrier has been created, so Mark marks events.
+func (c *Carrier) Attached() bool {
+	return c != nil && c.attached.Load()
+}
+
+// take returns the marked event with id and forgets it. It reports whether the mark was
+// overwritten by a newer one instead.
+func (c *Carrier) take(id uint64) (m Marked, ok, overwritten bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m = c.pending[id%carrierSlots]
+	if m.id != id {
+		return Marked{}, false, m.id > id
+	}
+	c.pending[id%carrierSlots] = Marked{}
+	return m, true, false
+}
+
+// Writer returns a zerolog.LevelWriter forwarding log lines to next and calling fn with
+// the marked events after they are written. The writer of a nil Carrier only forwards.
+func (c *Carrier) Writer(next io.Writer, fn func(m Marked, data []byte)) zerolog.LevelWriter {
+	if c != nil {
+		c.attached.Store(true)
+	}
+	return &carrierWriter{carrier: c, next: next, fn: fn}
+}
+
+type carrierWriter struct {
+	carrier *Carrier
+	next    io.Writer
+	fn      func(Marked, []byte)
+}
+
+func (w *carrierWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *carrierWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	n := len(p)
+	if w.carrier == nil {
+		return w.write(level, p)
+	}
+	ref := gjson.GetBytes(p, EscapePath(w.carrier.field))
+	if !ref.Exists() {
+		return w.write(level, p)
+	}
+
+	out := stripField(p, w.carrier.field, ref.Raw)
+	if _, err := w.write(level, out); err != nil {
+		return 0, err
+	}
+	m, ok, overwritten := w.carrier.take(ref.Uint())
+	if overwritten {
+		// more events than carrierSlots were in flight: handle the event without its context
+		m, ok = Marked{Ctx: context.Background(), Level: lineLevel(level, out)}, true
+	}
+	if ok {
+		w.fn(m, stripRefs(out))
+	}
+	return n, nil
+}
+
+// lineLevel returns level, or the level field of the encoded log line when level is
+// NoLevel, as passed by Write
+func lineLevel(level zerolog.Level, p []byte) zerolog.Level {
+	if level != zerolog.NoLevel {
+		return level
+	}
+	if lvl, err := zerolog.ParseLevel(gjson.GetBytes(p, EscapePath(zerolog.LevelFieldName)).String()); err == nil {
+		return lvl
+	}
+	return level
+}
+
+// stripRefs removes the reference fields of all carriers from the encoded log line
+func stripRefs(p []byte) []byte {
+	refFields.RLock()
+	defer refFields.RUnlock()
+	for _, name := range refFields.names {
+		if !bytes.Contains(p, []byte(strconv.Quote(name))) {
+			continue
+		}
+		if ref := gjson.GetBytes(p, EscapePath(name)); ref.Exists() {
+			p = stripField(p, name, ref.Raw)
+		}
+	}
+	return p
+}
+
+func (w *carrierWriter) write(level zerolog.Level, p []byte) (int, error) {
+	if w.next == nil {
+		return len(p), nil
+	}
+	if lw, ok := w.next.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.next.Write(p)
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *carrierWriter) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// stripField removes the field with the raw value from the encoded log line
+func stripField(p []byte, name string, raw string) []byte {
+	field := []byte(strconv.Quote(name) + ":" + raw)
+	idx := bytes.Index(p, field)
+	if idx < 0 {
+		return p
+	}
+	start, end := idx, idx+len(field)
+	if start > 0 && p[start-1] == ',' {
+		start--
+	} else if end < len(p) && p[end] == ',' {
+		end++
+	}
+	out := make([]byte, 0, len(p)-(end-start))
+	out = append(out, p[:start]...)
+	return append(out, p[end:]...)
+}
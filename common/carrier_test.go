@@ -0,0 +1,70 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// markHook marks every event on a Carrier
+type markHook struct{ c *Carrier }
+
+func (h markHook) Run(e *zerolog.Event, level zerolog.Level, _ string) { h.c.Mark(e, level) }
+
+func TestCarrierStripsAllRefs(t *testing.T) {
+	inner, outer := NewCarrier("_inner_ref"), NewCarrier("_outer_ref")
+	var out bytes.Buffer
+	var innerLine, outerLine string
+	w := outer.Writer(inner.Writer(&out, func(_ Marked, data []byte) {
+		innerLine = string(data)
+	}), func(_ Marked, data []byte) {
+		outerLine = string(data)
+	})
+	log := zerolog.New(w).Hook(markHook{inner}).Hook(markHook{outer})
+	log.Info().Str("k", "v").Msg("m")
+
+	want := `{"level":"info","k":"v","message":"m"}`
+	if got := out.String(); got != want+"\n" {
+		t.Errorf("output %s, want %s", got, want)
+	}
+	if innerLine != want+"\n" || outerLine != want+"\n" {
+		t.Errorf("handed %s and %s, want %s", innerLine, outerLine, want)
+	}
+}
+
+func TestCarrierWithoutWriter(t *testing.T) {
+	var out bytes.Buffer
+	log := zerolog.New(&out).Hook(markHook{NewCarrier("_ref")}).Hook(markHook{nil})
+	log.Info().Msg("m")
+	if want := `{"level":"info","message":"m"}` + "\n"; out.String() != want {
+		t.Errorf("output %s, want %s", out.String(), want)
+	}
+}
+
+func TestCarrierOverwrittenMark(t *testing.T) {
+	c := NewCarrier("_ref")
+	var handled []Marked
+	w := c.Writer(nil, func(m Marked, _ []byte) { handled = append(handled, m) })
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, true)
+	log := zerolog.New(io.Discard)
+	c.Mark(log.Error().Ctx(ctx), zerolog.ErrorLevel)
+	for i := 0; i < carrierSlots; i++ {
+		c.Mark(log.Info(), zerolog.InfoLevel)
+	}
+	// the line of the first event, whose mark was overwritten
+	if _, err := w.Write([]byte(`{"level":"error","_ref":1,"message":"m"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 1 {
+		t.Fatalf("handled %d events, want 1", len(handled))
+	}
+	if handled[0].Level != zerolog.ErrorLevel || handled[0].Ctx.Value(key{}) != nil {
+		t.Errorf("handled %+v, want the error level without the context", handled[0])
+	}
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// MaxFieldDepth is the nesting depth up to which FieldValue decodes arrays and objects.
+// Deeper values are kept as their raw JSON text.
+const MaxFieldDepth = 32
+
+// FieldValue decodes a JSON value into its native Go type, keeping integers as int64
+func FieldValue(value gjson.Result) interface{} {
+	return fieldValue(value, 0)
+}
+
+func fieldValue(value gjson.Result, depth int) interface{} {
+	switch value.Type {
+	case gjson.Null:
+		return nil
+	case gjson.False, gjson.True:
+		return value.Bool()
+	case gjson.Number:
+		if i, err := strconv.ParseInt(value.Raw, 10, 64); err == nil {
+			return i
+		}
+		return value.Float()
+	case gjson.String:
+		return value.String()
+	}
+	if depth >= MaxFieldDepth {
+		return value.Raw
+	}
+	if value.IsArray() {
+		arr := value.Array()
+		items := make([]interface{}, 0, len(arr))
+		for _, v := range arr {
+			items = append(items, fieldValue(v, depth+1))
+		}
+		return items
+	}
+	if value.IsObject() {
+		obj := make(map[string]interface{})
+		value.ForEach(func(k, v gjson.Result) bool {
+			obj[k.String()] = fieldValue(v, depth+1)
+			return true
+		})
+		return obj
+	}
+	return value.String()
+}
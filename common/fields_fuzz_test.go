@@ -0,0 +1,53 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func FuzzFieldValue(f *testing.F) {
+	seeds := []string{
+		`1`, `-1.5`, `12345678901234567890`, `"s"`, `true`, `null`,
+		`{"a":[1,"b",{"c":null}]}`,
+		`{"a":`, `[1,`, "\"\xff\"",
+		strings.Repeat(`[`, MaxFieldDepth+10) + strings.Repeat(`]`, MaxFieldDepth+10),
+		strings.Repeat(`{"a":`, MaxFieldDepth+10) + `1` + strings.Repeat(`}`, MaxFieldDepth+10),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		v := FieldValue(gjson.Parse(raw))
+		if _, err := json.Marshal(v); err != nil {
+			t.Fatalf("value of %q cannot be encoded: %v", raw, err)
+		}
+		if d := depth(v); d > MaxFieldDepth+1 {
+			t.Fatalf("value of %q is %d levels deep", raw, d)
+		}
+	})
+}
+
+// depth returns the nesting depth of a decoded JSON value
+func depth(v interface{}) int {
+	max := 0
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if d := depth(item); d > max {
+				max = d
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if d := depth(item); d > max {
+				max = d
+			}
+		}
+	default:
+		return 0
+	}
+	return max + 1
+}
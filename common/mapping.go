@@ -0,0 +1,24 @@
+package common
+
+// UserFields names the log fields mapped to the Sentry user attributes by the Sentry hook
+// and writer. Empty names are not mapped.
+type UserFields struct {
+	ID        string
+	Email     string
+	Username  string
+	Name      string
+	IPAddress string
+}
+
+// EscapePath escapes gjson path syntax so field is looked up literally
+func EscapePath(field string) string {
+	var escaped []byte
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '.', '*', '?', '|', '#', '@', '\\', '!', '=', '<', '>', '%':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, field[i])
+	}
+	return string(escaped)
+}
@@ -0,0 +1,118 @@
+package common
+
+import (
+	"fmt"
+	"go/build"
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// FieldPanicType holds the Go type of a recovered panic value.
+const FieldPanicType = "panic_type"
+
+// PanicValue returns the fields of a recovered panic value: the error field with the value,
+// panic_type with its Go type, and the stack field with the stack of the panicking
+// goroutine in the shape the Sentry writer reads, without the frames of the recovery.
+// Call it in the deferred function that recovered:
+//
+//	if rec := recover(); rec != nil {
+//		l.WithLevel(zerolog.PanicLevel).EmbedObject(common.PanicValue(rec)).Msg("panic recovered")
+//	}
+func PanicValue(rec interface{}) zerolog.LogObjectMarshaler {
+	return panicValue{rec: rec, stack: panicStack()}
+}
+
+type panicValue struct {
+	rec   interface{}
+	stack []frame
+}
+
+func (p panicValue) MarshalZerologObject(e *zerolog.Event) {
+	msg := fmt.Sprint(p.rec)
+	if err, ok := p.rec.(error); ok {
+		e.AnErr(zerolog.ErrorFieldName, err)
+	} else {
+		e.Str(zerolog.ErrorFieldName, msg)
+	}
+	e.Str(FieldPanicType, fmt.Sprintf("%T", p.rec)).
+		Interface(zerolog.ErrorStackFieldName, errWithStack{Stacktrace: stacktrace{Frames: p.stack}, Err: msg})
+}
+
+// errWithStack, stacktrace and frame encode a stack like the Sentry SDK, which the Sentry
+// hook and writer decode
+type errWithStack struct {
+	Stacktrace stacktrace `json:"stacktrace"`
+	Err        string     `json:"error"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+var goRoot = strings.ReplaceAll(build.Default.GOROOT, "\\", "/")
+
+// newFrame converts f like sentry.NewFrame
+func newFrame(f runtime.Frame) frame {
+	fr := frame{Function: f.Function, Lineno: f.Line}
+	// the package path ends at the first dot after the last slash
+	slash := strings.LastIndexByte(f.Function, '/')
+	if dot := strings.IndexByte(f.Function[slash+1:], '.'); dot >= 0 {
+		fr.Module, fr.Function = f.Function[:slash+1+dot], f.Function[slash+2+dot:]
+	}
+	switch {
+	case f.File == "":
+		fr.Filename = "unknown"
+	case strings.HasPrefix(f.File, "/") || len(f.File) > 2 && f.File[1] == ':':
+		fr.AbsPath = f.File
+	default:
+		fr.Filename = f.File
+	}
+	fr.InApp = !strings.HasPrefix(fr.AbsPath, goRoot) &&
+		!strings.Contains(fr.Module, "vendor") && !strings.Contains(fr.Module, "third_party")
+	return fr
+}
+
+// panicStack returns the stack of the panicking goroutine, from its start to the call of
+// panic, when called by a deferred function during a panic. Otherwise it returns the stack
+// of the caller of PanicValue. Frames are ordered from the outermost call, as in Sentry.
+func panicStack() []frame {
+	pcs := make([]uintptr, 100)
+	// skip runtime.Callers, panicStack and PanicValue
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []runtime.Frame
+	panicking := false
+	for {
+		f, more := frames.Next()
+		if f.Function == "runtime.gopanic" && !panicking {
+			// drop the frames of the recovery
+			panicking, stack = true, stack[:0]
+		} else {
+			stack = append(stack, f)
+		}
+		if !more {
+			break
+		}
+	}
+	st := make([]frame, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		fr := newFrame(stack[i])
+		// skip the frames sentry.NewStacktrace skips
+		if fr.Module == "runtime" || fr.Module == "testing" {
+			continue
+		}
+		st = append(st, fr)
+	}
+	return st
+}
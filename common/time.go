@@ -0,0 +1,37 @@
+package common
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// ParseTime decodes a time field encoded by zerolog according to zerolog.TimeFieldFormat.
+func ParseTime(value gjson.Result) (time.Time, bool) {
+	if value.Type == gjson.Number {
+		switch zerolog.TimeFieldFormat {
+		case zerolog.TimeFormatUnixMs:
+			return time.UnixMilli(value.Int()), true
+		case zerolog.TimeFormatUnixMicro:
+			return time.UnixMicro(value.Int()), true
+		case zerolog.TimeFormatUnixNano:
+			return time.Unix(0, value.Int()), true
+		default:
+			sec := value.Float()
+			return time.Unix(0, int64(sec*float64(time.Second))), true
+		}
+	}
+	if value.Type != gjson.String {
+		return time.Time{}, false
+	}
+	format := zerolog.TimeFieldFormat
+	if format == "" {
+		format = time.RFC3339
+	}
+	t, err := time.Parse(format, value.String())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
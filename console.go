@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const consoleTimeFormat = "15:04:05.000"
+
+// ConsoleTheme sets the colors of the Console format, as ANSI SGR parameters such as "31" for
+// red, "1;31" for bold red or "2" for dim.
+type ConsoleTheme struct {
+	// Levels are the colors of the level labels.
+	Levels map[zerolog.Level]string
+	// Key is the color of the field keys.
+	Key string
+	// Icons are written before the level labels, also without colors.
+	Icons map[zerolog.Level]string
+}
+
+// DefaultConsoleTheme has the colors of zerolog.ConsoleWriter.
+var DefaultConsoleTheme = ConsoleTheme{
+	Levels: levelColors(),
+	Key:    "36",
+}
+
+// DimConsoleTheme dims the field keys so messages and values stand out.
+var DimConsoleTheme = ConsoleTheme{
+	Levels: levelColors(),
+	Key:    "2",
+}
+
+// LevelIcons are level icons for ConsoleTheme.Icons, see WithLevelIcons.
+var LevelIcons = map[zerolog.Level]string{
+	zerolog.TraceLevel: "·",
+	zerolog.DebugLevel: "•",
+	zerolog.InfoLevel:  "ℹ",
+	zerolog.WarnLevel:  "⚠",
+	zerolog.ErrorLevel: "✖",
+	zerolog.FatalLevel: "☠",
+	zerolog.PanicLevel: "‼",
+}
+
+func levelColors() map[zerolog.Level]string {
+	colors := make(map[zerolog.Level]string, len(zerolog.LevelColors))
+	for level, c := range zerolog.LevelColors {
+		colors[level] = strconv.Itoa(c)
+	}
+	return colors
+}
+
+// ColorDisabled reports whether the environment asks for output without colors: NO_COLOR
+// is set to a non-empty value, the program runs in CI, or TERM is "dumb".
+func ColorDisabled() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("CI") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// NewConsoleWriter returns the zerolog.ConsoleWriter of the Console format, which prints the
+// error stack below the line, one frame per line. Colors are disabled by noColor or
+// ColorDisabled.
+func NewConsoleWriter(out io.Writer, noColor bool) zerolog.ConsoleWriter {
+	return DefaultConsoleTheme.Writer(out, noColor)
+}
+
+// Writer returns the zerolog.ConsoleWriter of the Console format in the colors of the theme.
+// Colors are disabled by noColor or ColorDisabled.
+func (t ConsoleTheme) Writer(out io.Writer, noColor bool) zerolog.ConsoleWriter {
+	noColor = noColor || ColorDisabled()
+	return zerolog.ConsoleWriter{
+		Out:                out,
+		NoColor:            noColor,
+		TimeFormat:         consoleTimeFormat,
+		FieldsExclude:      []string{zerolog.ErrorStackFieldName},
+		FormatExtra:        formatStack,
+		FormatLevel:        t.formatLevel(noColor),
+		FormatFieldName:    t.formatFieldName(noColor),
+		FormatErrFieldName: t.formatFieldName(noColor),
+	}
+}
+
+func (t ConsoleTheme) formatLevel(noColor bool) zerolog.Formatter {
+	return func(i interface{}) string {
+		s, _ := i.(string)
+		level, err := zerolog.ParseLevel(s)
+		label, ok := zerolog.FormattedLevels[level]
+		if err != nil || !ok {
+			if s == "" {
+				return "???"
+			}
+			return strings.ToUpper(s)
+		}
+		if !noColor {
+			label = colorize(label, t.Levels[level])
+		}
+		if icon := t.Icons[level]; icon != "" {
+			label = icon + " " + label
+		}
+		return label
+	}
+}
+
+func (t ConsoleTheme) formatFieldName(noColor bool) zerolog.Formatter {
+	return func(i interface{}) string {
+		name := fmt.Sprintf("%s=", i)
+		if noColor {
+			return name
+		}
+		return colorize(name, t.Key)
+	}
+}
+
+// colorize wraps s in the SGR parameters of color
+func colorize(s, color string) string {
+	if color == "" {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+// formatStack writes the stack field one frame per line, innermost first. It understands the
+// frames written by github.com/rs/zerolog/pkgerrors, the Sentry stacktraces written by
+// common.PanicValue and plain multi-line strings.
+func formatStack(evt map[string]interface{}, buf *bytes.Buffer) error {
+	switch stack := evt[zerolog.ErrorStackFieldName].(type) {
+	case string:
+		for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+			buf.WriteString("\n    ")
+			buf.WriteString(strings.TrimSpace(line))
+		}
+	case []interface{}:
+		for _, frame := range stack {
+			f, ok := frame.(map[string]interface{})
+			if !ok {
+				fmt.Fprintf(buf, "\n    %v", frame)
+				continue
+			}
+			fmt.Fprintf(buf, "\n    at %v (%v:%v)", f["func"], f["source"], f["line"])
+		}
+	case map[string]interface{}:
+		st, _ := stack["stacktrace"].(map[string]interface{})
+		frames, _ := st["frames"].([]interface{})
+		for i := len(frames) - 1; i >= 0; i-- {
+			f, ok := frames[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			file := f["abs_path"]
+			if file == nil {
+				file = f["filename"]
+			}
+			fmt.Fprintf(buf, "\n    at %v.%v (%v:%v)", f["module"], f["function"], file, f["lineno"])
+		}
+	}
+	return nil
+}
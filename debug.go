@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DebugHeader is the header carrying a debug token, see NewDebugToken; gRPC metadata uses
+// its lower case.
+const DebugHeader = "X-Debug-Token"
+
+// FieldDebug marks the events of loggers lowered to debug by WithDebug.
+const FieldDebug = "debug"
+
+type debugKey struct{}
+
+// NewDebugToken returns a token asking for the debug logs of a request until ttl elapses,
+// for the middlewares configured with the same secret. It is the expiry time and its
+// HMAC-SHA256, so it can be handed to a caller without sharing the secret.
+func NewDebugToken(secret []byte, ttl time.Duration) string {
+	expiry := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return expiry + "." + debugMAC(secret, expiry)
+}
+
+// VerifyDebugToken reports whether token was created by NewDebugToken with secret and has
+// not expired. Tokens never verify with an empty secret.
+func VerifyDebugToken(secret []byte, token string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	expiry, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(debugMAC(secret, expiry)))
+}
+
+func debugMAC(secret []byte, expiry string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(expiry))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// WithDebug returns a copy of ctx asking for debug logs, whose logger, if any, is lowered
+// to debug with DebugLogger. The middlewares of this module honor it for the requests they
+// serve with ctx. zerolog.SetGlobalLevel still applies.
+func WithDebug(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, debugKey{}, true)
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		ctx = DebugLogger(*l).WithContext(ctx)
+	}
+	return ctx
+}
+
+// DebugRequested reports whether ctx was returned by WithDebug or token is a valid debug
+// token for secret.
+func DebugRequested(ctx context.Context, secret []byte, token string) bool {
+	if v, _ := ctx.Value(debugKey{}).(bool); v {
+		return true
+	}
+	return token != "" && VerifyDebugToken(secret, token)
+}
+
+// DebugLogger returns l at debug level with the debug field, or l if it already logs
+// debug events.
+func DebugLogger(l zerolog.Logger) zerolog.Logger {
+	if l.GetLevel() <= zerolog.DebugLevel {
+		return l
+	}
+	return l.Level(zerolog.DebugLevel).With().Bool(FieldDebug, true).Logger()
+}
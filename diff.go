@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+// Change is a value which differs between two states.
+type Change struct {
+	// Path is the dotted path of the value, array elements being addressed by index. It is
+	// empty when the states themselves differ, e.g. two different numbers.
+	Path string
+	// From is the value before, nil when it was added.
+	From interface{}
+	// To is the value after, nil when it was removed.
+	To interface{}
+	// Added and Removed report that the value exists in one state only.
+	Added, Removed bool
+}
+
+// Differ computes the changes between two states.
+type Differ func(before, after interface{}) []Change
+
+// DefaultDiffer is the Differ of Diff. It compares the JSON encodings of the states and
+// reports the changed leaves, in path order. Arrays of different lengths are reported as
+// one change.
+var DefaultDiffer Differ = StructuralDiff
+
+// Diff returns the changes between before and after as an object, for Event.Object or
+// Context.Object, with one {"from":...,"to":...} entry per changed path. A change of the
+// states themselves is keyed DiffRootKey:
+//
+//	logger.Info().Object("diff", logger.Diff(oldConfig, newConfig)).Msg("config updated")
+func Diff(before, after interface{}) zerolog.LogObjectMarshaler {
+	return DiffWith(DefaultDiffer, before, after)
+}
+
+// DiffWith is Diff computing the changes with differ.
+func DiffWith(differ Differ, before, after interface{}) zerolog.LogObjectMarshaler {
+	return changes(differ(before, after))
+}
+
+// DiffRootKey is the key of the change of the states themselves, e.g. two different numbers,
+// in the object of Diff.
+const DiffRootKey = "value"
+
+type changes []Change
+
+func (c changes) MarshalZerologObject(e *zerolog.Event) {
+	for _, ch := range c {
+		d := zerolog.Dict()
+		if !ch.Added {
+			d.Interface("from", ch.From)
+		}
+		if !ch.Removed {
+			d.Interface("to", ch.To)
+		}
+		key := ch.Path
+		if key == "" {
+			key = DiffRootKey
+		}
+		e.Dict(key, d)
+	}
+}
+
+// StructuralDiff is the default Differ.
+func StructuralDiff(before, after interface{}) []Change {
+	var out []Change
+	diffValues(&out, "", normalizeJSON(before), normalizeJSON(after))
+	return out
+}
+
+// normalizeJSON returns v as decoded from its JSON encoding, or v if it cannot be encoded
+func normalizeJSON(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func diffValues(out *[]Change, path string, before, after interface{}) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(a))
+		for k := range b {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := b[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			bv, inBefore := b[k]
+			av, inAfter := a[k]
+			p := joinPath(path, k)
+			switch {
+			case !inAfter:
+				*out = append(*out, Change{Path: p, From: bv, Removed: true})
+			case !inBefore:
+				*out = append(*out, Change{Path: p, To: av, Added: true})
+			default:
+				diffValues(out, p, bv, av)
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range b {
+			diffValues(out, joinPath(path, strconv.Itoa(i)), b[i], a[i])
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*out = append(*out, Change{Path: path, From: before, To: after})
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,170 @@
+// Package events logs business and domain events, such as an order being placed or a plan
+// being changed, in a stable shape for analytics consumers.
+//
+// An event is written as a regular log line with every event field under the reserved
+// Namespace key:
+//
+//	{"level":"info","event":{"v":1,"action":"order.updated","actor":"user:42",
+//	"entity":{"type":"order","id":"1001"},"changes":{"status":{"from":"new","to":"paid"}}},
+//	"message":"order.updated"}
+//
+// Consumers select lines holding the Namespace key; other fields of the line, like the
+// request ID, are left to the logger.
+package events
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/XiBao/logger"
+	"github.com/rs/zerolog"
+)
+
+// Namespace is the field holding the event. Code should not log other fields under it.
+const Namespace = "event"
+
+// Version is the version of the event shape, written in the v field.
+const Version = 1
+
+// Field names inside Namespace.
+const (
+	FieldVersion = "v"
+	FieldAction  = "action"
+	FieldActor   = "actor"
+	FieldEntity  = "entity"
+	FieldType    = "type"
+	FieldID      = "id"
+	FieldChanges = "changes"
+	FieldFrom    = "from"
+	FieldTo      = "to"
+	FieldAttrs   = "attrs"
+)
+
+var _ = zerolog.LogObjectMarshaler(new(Event))
+
+// Event is a domain event being built. It is not safe for concurrent use.
+type Event struct {
+	action     string
+	actor      string
+	entityType string
+	entityID   string
+	before     map[string]any
+	after      map[string]any
+	attrs      map[string]any
+}
+
+// New starts an event of action, e.g. "order.updated".
+func New(action string) *Event {
+	return &Event{action: action}
+}
+
+// Actor sets who performed the action, e.g. "user:42".
+func (e *Event) Actor(actor string) *Event {
+	e.actor = actor
+	return e
+}
+
+// Entity sets the type and ID of the entity the action applies to.
+func (e *Event) Entity(typ, id string) *Event {
+	e.entityType = typ
+	e.entityID = id
+	return e
+}
+
+// Before sets the state of the entity before the action.
+func (e *Event) Before(state map[string]any) *Event {
+	e.before = state
+	return e
+}
+
+// After sets the state of the entity after the action. Only the keys whose values differ
+// from Before are written, in the changes field.
+func (e *Event) After(state map[string]any) *Event {
+	e.after = state
+	return e
+}
+
+// Attr adds an attribute of the event, written in the attrs field.
+func (e *Event) Attr(key string, value any) *Event {
+	if e.attrs == nil {
+		e.attrs = make(map[string]any)
+	}
+	e.attrs[key] = value
+	return e
+}
+
+// Send logs the event at Info level with the logger of ctx, or the global logger when ctx
+// has none.
+func (e *Event) Send(ctx context.Context) {
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		l = &logger.Logger
+	}
+	e.Log(l)
+}
+
+// Log logs the event at Info level with l.
+func (e *Event) Log(l *zerolog.Logger) {
+	l.Info().Object(Namespace, e).Msg(e.action)
+}
+
+// implements zerolog.LogObjectMarshaler
+func (e *Event) MarshalZerologObject(enc *zerolog.Event) {
+	enc.Int(FieldVersion, Version).Str(FieldAction, e.action)
+	if e.actor != "" {
+		enc.Str(FieldActor, e.actor)
+	}
+	if e.entityType != "" || e.entityID != "" {
+		enc.Dict(FieldEntity, zerolog.Dict().
+			Str(FieldType, e.entityType).
+			Str(FieldID, e.entityID))
+	}
+	if keys := e.changed(); len(keys) > 0 {
+		changes := zerolog.Dict()
+		for _, k := range keys {
+			change := zerolog.Dict()
+			if v, ok := e.before[k]; ok {
+				change.Interface(FieldFrom, v)
+			}
+			if v, ok := e.after[k]; ok {
+				change.Interface(FieldTo, v)
+			}
+			changes.Dict(k, change)
+		}
+		enc.Dict(FieldChanges, changes)
+	}
+	if len(e.attrs) > 0 {
+		attrs := zerolog.Dict()
+		for _, k := range sortedKeys(e.attrs) {
+			attrs.Interface(k, e.attrs[k])
+		}
+		enc.Dict(FieldAttrs, attrs)
+	}
+}
+
+// changed returns the sorted keys whose values differ between before and after
+func (e *Event) changed() []string {
+	var keys []string
+	for k, v := range e.before {
+		if after, ok := e.after[k]; !ok || !reflect.DeepEqual(v, after) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range e.after {
+		if _, ok := e.before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/XiBao/logger/internal/jsonenc"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// FieldNames renames the standard fields in the lines of a logger created with New. Empty
+// names keep zerolog's global name.
+type FieldNames struct {
+	Error     string
+	Message   string
+	Timestamp string
+	Stack     string
+}
+
+// renames maps the global zerolog names to the configured ones
+func (n FieldNames) renames() map[string]string {
+	m := make(map[string]string, 4)
+	for from, to := range map[string]string{
+		zerolog.ErrorFieldName:      n.Error,
+		zerolog.MessageFieldName:    n.Message,
+		zerolog.TimestampFieldName:  n.Timestamp,
+		zerolog.ErrorStackFieldName: n.Stack,
+	} {
+		if to != "" && to != from {
+			m[from] = to
+		}
+	}
+	return m
+}
+
+// renameWriter renames the top-level keys of the encoded lines. zerolog encodes the names
+// of the standard fields from process-wide variables, so loggers needing other names
+// rename them on output.
+type renameWriter struct {
+	next    io.Writer
+	renames map[string]string
+}
+
+func (w *renameWriter) Write(p []byte) (int, error) {
+	if _, err := w.next.Write(w.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// implements zerolog.LevelWriter
+func (w *renameWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.next.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	if _, err := lw.WriteLevel(level, w.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *renameWriter) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (w *renameWriter) rename(p []byte) []byte {
+	line := bytes.TrimRight(p, "\n")
+	res := gjson.ParseBytes(line)
+	if !res.IsObject() {
+		return p
+	}
+	out := make([]byte, 0, len(p)+16)
+	out = append(out, '{')
+	res.ForEach(func(key, value gjson.Result) bool {
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		if to, ok := w.renames[key.String()]; ok {
+			out = jsonenc.AppendString(out, to)
+		} else {
+			out = append(out, key.Raw...)
+		}
+		out = append(out, ':')
+		out = append(out, value.Raw...)
+		return true
+	})
+	out = append(out, '}')
+	return append(out, p[len(line):]...)
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Field is a key and value, for the non-chained Emit API. Fields can be built ahead and
+// reused across calls.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns the Field key=value.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Emit writes msg at level to l with fields, as an alternative to chaining:
+//
+//	logger.Emit(&l, zerolog.InfoLevel, "user created", logger.F("id", id), logger.F("admin", false))
+//
+// Values of common types are encoded with the matching typed method of zerolog.Event,
+// errors with AnErr, others with Interface.
+func Emit(l *zerolog.Logger, level zerolog.Level, msg string, fields ...Field) {
+	e := l.WithLevel(level)
+	if e == nil {
+		return
+	}
+	for _, f := range fields {
+		e = appendField(e, f)
+	}
+	e.CallerSkipFrame(1).Msg(msg)
+}
+
+func appendField(e *zerolog.Event, f Field) *zerolog.Event {
+	switch v := f.Value.(type) {
+	case string:
+		return e.Str(f.Key, v)
+	case []byte:
+		return e.Bytes(f.Key, v)
+	case bool:
+		return e.Bool(f.Key, v)
+	case int:
+		return e.Int(f.Key, v)
+	case int8:
+		return e.Int8(f.Key, v)
+	case int16:
+		return e.Int16(f.Key, v)
+	case int32:
+		return e.Int32(f.Key, v)
+	case int64:
+		return e.Int64(f.Key, v)
+	case uint:
+		return e.Uint(f.Key, v)
+	case uint8:
+		return e.Uint8(f.Key, v)
+	case uint16:
+		return e.Uint16(f.Key, v)
+	case uint32:
+		return e.Uint32(f.Key, v)
+	case uint64:
+		return e.Uint64(f.Key, v)
+	case float32:
+		return e.Float32(f.Key, v)
+	case float64:
+		return e.Float64(f.Key, v)
+	case time.Time:
+		return e.Time(f.Key, v)
+	case time.Duration:
+		return e.Dur(f.Key, v)
+	case error:
+		return e.AnErr(f.Key, v)
+	case zerolog.LogObjectMarshaler:
+		return e.Object(f.Key, v)
+	case zerolog.LogArrayMarshaler:
+		return e.Array(f.Key, v)
+	case nil:
+		return e.Interface(f.Key, nil)
+	}
+	return e.Interface(f.Key, f.Value)
+}
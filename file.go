@@ -2,12 +2,9 @@ package logger
 
 import (
 	"io"
-	"os"
-	"path/filepath"
-	"time"
 
-	"github.com/rs/zerolog/diode"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"github.com/XiBao/logger/writer/diode"
+	"github.com/XiBao/logger/writer/rotate"
 )
 
 type FileConfig struct {
@@ -35,19 +32,34 @@ type FileConfig struct {
 	// Compress determines if the rotated log files should be compressed
 	// using gzip. The default is not to perform compression.
 	Compress bool
+
+	// ReopenOnSIGHUP reopens the log file when the process receives SIGHUP,
+	// for files moved aside by logrotate.
+	ReopenOnSIGHUP bool
+
+	// OnDropped is called with the number of log lines dropped because the
+	// file could not keep up with the logger.
+	OnDropped func(missed int)
 }
 
 func File(filename string, c FileConfig) (io.Writer, error) {
-	if err := os.MkdirAll(filepath.Dir(filename), 0744); err != nil {
-		return nil, err
+	opts := []rotate.WriterOption{
+		rotate.WithMaxSize(c.MaxSize),
+		rotate.WithMaxAge(c.MaxAge),
+		rotate.WithMaxBackups(c.MaxBackups),
+	}
+	if c.LocalTime {
+		opts = append(opts, rotate.WithLocalTime())
 	}
-	fd := &lumberjack.Logger{
-		Filename:   filename,
-		MaxSize:    c.MaxSize,
-		MaxBackups: c.MaxBackups,
-		MaxAge:     c.MaxAge,
-		Compress:   c.Compress,
-		LocalTime:  c.LocalTime,
+	if c.Compress {
+		opts = append(opts, rotate.WithCompress())
+	}
+	if c.ReopenOnSIGHUP {
+		opts = append(opts, rotate.WithReopenOnSIGHUP())
+	}
+	fd, err := rotate.New(filename, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return diode.NewWriter(fd, 1000, 10*time.Millisecond, nil), nil
+	return diode.New(fd, diode.WithAlert(c.OnDropped)), nil
 }
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/XiBao/logger/writer/cbor"
+	"github.com/rs/zerolog"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// goldenEvent logs the same event through every format: context fields, scalars, an array,
+// a dict, an error with its stack and a message needing quotes
+func goldenEvent(l zerolog.Logger) {
+	l = l.With().Str("service", "golden").Logger()
+	l.Error().
+		Str("user", "ada lovelace").
+		Int("status", 500).
+		Float64("ratio", 0.25).
+		Bool("retry", false).
+		Dur("latency", 1500*time.Millisecond).
+		Array("tags", Array("a", 2, true)).
+		Dict("request", zerolog.Dict().Str("method", "GET").Str("path", "/x")).
+		Stack().
+		Err(errors.New("boom")).
+		Msg(`failed "twice"`)
+}
+
+// TestFormatsGolden compares the line of goldenEvent in each format to its golden file
+// in testdata. Run go test -update to rewrite them. The CBOR line is also decoded and
+// compared to the JSON one, as both encode the same fields.
+func TestFormatsGolden(t *testing.T) {
+	defer func(ts func() time.Time, stack func(error) interface{}, local *time.Location) {
+		zerolog.TimestampFunc, zerolog.ErrorStackMarshaler, time.Local = ts, stack, local
+	}(zerolog.TimestampFunc, zerolog.ErrorStackMarshaler, time.Local)
+	// the Console format writes the time in the local time zone
+	time.Local = time.UTC
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2024, 7, 22, 16, 17, 16, 0, time.UTC) }
+	zerolog.ErrorStackMarshaler = func(error) interface{} {
+		return []map[string]string{{"func": "main.run", "line": "42", "source": "main.go"}}
+	}
+
+	outputs := make(map[Format][]byte, len(formats))
+	for _, f := range formats {
+		var out bytes.Buffer
+		goldenEvent(New(WithOutput(&out), WithFormat(f.format), WithNoColor()))
+		outputs[f.format] = out.Bytes()
+		if f.format == CBOR {
+			continue
+		}
+		golden := filepath.Join("testdata", "golden."+f.name)
+		if *update {
+			if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), want) {
+			t.Errorf("%s output differs from %s:\n got: %s\nwant: %s", f.name, golden, out.Bytes(), want)
+		}
+	}
+
+	var decoded bytes.Buffer
+	if err := cbor.Decode(&decoded, bytes.NewReader(outputs[CBOR])); err != nil {
+		t.Fatalf("decode CBOR: %v", err)
+	}
+	var fromJSON, fromCBOR map[string]interface{}
+	if err := json.Unmarshal(outputs[JSON], &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(decoded.Bytes(), &fromCBOR); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromCBOR) {
+		t.Errorf("CBOR fields differ from JSON:\n got: %v\nwant: %v", fromCBOR, fromJSON)
+	}
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names of the goroutine and worker tags.
+const (
+	FieldGoroutineID = "goroutine_id"
+	FieldWorkerID    = "worker_id"
+)
+
+// goroutineHook adds the ID of the goroutine writing the event
+type goroutineHook struct{}
+
+func (goroutineHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if id := goroutineID(); id > 0 {
+		e.Uint64(FieldGoroutineID, id)
+	}
+}
+
+// goroutineID parses the ID of the current goroutine from the header of its stack,
+// "goroutine 42 [running]:", reading only the first 64 bytes of the stack.
+// The ID is meant for debugging only; it is 0 when the header cannot be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	const prefix = "goroutine "
+	if len(b) <= len(prefix) || string(b[:len(prefix)]) != prefix {
+		return 0
+	}
+	var id uint64
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
+
+// GoroutineLogger returns l with the ID of the current goroutine as a goroutine_id field.
+// The ID is read once, so it is the cached alternative to WithGoroutineID for long-lived
+// goroutines, e.g. the workers of a pool; the returned logger must not be shared with
+// other goroutines.
+//
+//	go func() {
+//		l := logger.GoroutineLogger(base)
+//		for job := range jobs {
+//			l.Info().Str("job", job.ID).Msg("processing")
+//		}
+//	}()
+func GoroutineLogger(l zerolog.Logger) zerolog.Logger {
+	id := goroutineID()
+	if id == 0 {
+		return l
+	}
+	return l.With().Uint64(FieldGoroutineID, id).Logger()
+}
+
+type workerKey struct{}
+
+// WithWorkerID returns a copy of ctx carrying the ID of the worker, e.g. its index in a
+// pool, to correlate the interleaved events of concurrent workers. The logger of ctx, if
+// any, gets the worker_id field; events of other loggers get it with Provider(WorkerID)
+// and Event.Ctx.
+func WithWorkerID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, workerKey{}, id)
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		ctx = l.With().Str(FieldWorkerID, id).Logger().WithContext(ctx)
+	}
+	return ctx
+}
+
+// WorkerID provides the worker ID set with WithWorkerID, use it as Provider(WorkerID).
+func WorkerID(ctx context.Context) map[string]interface{} {
+	id, ok := ctx.Value(workerKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{FieldWorkerID: id}
+}
@@ -0,0 +1,282 @@
+// Package alert posts high-severity zerolog events to Slack, Microsoft Teams or any HTTP endpoint.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// FieldRef is the field the hook marks alerted events with. The paired writer removes it
+// before the log line reaches the wrapped writer.
+const FieldRef = "_alert_ref"
+
+// Format selects the payload posted to the endpoint.
+type Format int
+
+const (
+	// Slack posts {"text": ...} to a Slack incoming webhook.
+	Slack Format = iota
+	// Teams posts a MessageCard to a Microsoft Teams incoming webhook.
+	Teams
+	// Generic posts the rendered text together with the event as JSON.
+	Generic
+)
+
+// DefaultTemplate renders the alert text.
+const DefaultTemplate = `[{{ .Level }}] {{ .Message }}{{ if .Error }}: {{ .Error }}{{ end }}`
+
+// Alert is the data the message template is executed with.
+type Alert struct {
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Error   string                 `json:"error,omitempty"`
+	Time    time.Time              `json:"time"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Hook posts error, fatal and panic events to a webhook, at most a configured number per
+// interval and once per deduplication window for identical alerts. Alerts are sent from a
+// background goroutine, except for fatal and panic events which are sent before the program
+// terminates.
+//
+// The fields of the event are read from the encoded log line, so the writer returned by
+// Hook.Writer must wrap the output of the logger. Callers must call Close when done, so the
+// queued alerts are sent and the goroutine stops:
+//
+//	h, _ := alert.NewHook(webhookURL)
+//	defer h.Close(context.Background())
+//	log := zerolog.New(h.Writer(os.Stderr)).Hook(h)
+type Hook struct {
+	url     string
+	cfg     config
+	tmpl    *template.Template
+	carrier *common.Carrier
+	queue   chan Alert
+	done    chan struct{}
+
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	seen        map[uint64]time.Time
+}
+
+// NewHook creates a hook posting to the webhook url.
+func NewHook(url string, opts ...HookOption) (*Hook, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("alert", cfg.onError)
+	tmpl, err := template.New("alert").Parse(cfg.template)
+	if err != nil {
+		return nil, err
+	}
+	h := &Hook{
+		url:     url,
+		cfg:     cfg,
+		tmpl:    tmpl,
+		carrier: common.NewCarrier(FieldRef),
+		queue:   make(chan Alert, 64),
+		done:    make(chan struct{}),
+		seen:    make(map[uint64]time.Time),
+	}
+	go h.run()
+	return h, nil
+}
+
+func (h *Hook) Run(event *zerolog.Event, level zerolog.Level, message string) {
+	if level < h.cfg.minLevel || level > zerolog.PanicLevel {
+		return
+	}
+	h.carrier.Mark(event, level)
+}
+
+// Writer returns a zerolog.LevelWriter forwarding log lines to w and alerting on the events
+// marked by the hook.
+func (h *Hook) Writer(w io.Writer) zerolog.LevelWriter {
+	return h.carrier.Writer(w, h.alert)
+}
+
+// Notify alerts on an encoded log line at level, as if its event was marked by the hook.
+// It lets writers raise alerts, e.g. on escalated events.
+func (h *Hook) Notify(level zerolog.Level, data []byte) {
+	h.alert(common.Marked{Ctx: context.Background(), Level: level}, data)
+}
+
+func (h *Hook) alert(m common.Marked, data []byte) {
+	a := Alert{
+		Level:  m.Level.String(),
+		Time:   time.Now(),
+		Fields: make(map[string]interface{}),
+	}
+	gjson.ParseBytes(data).ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.LevelFieldName:
+		case zerolog.MessageFieldName:
+			a.Message = value.String()
+		case zerolog.ErrorFieldName:
+			a.Error = value.String()
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				a.Time = t
+			}
+		default:
+			a.Fields[key.String()] = common.FieldValue(value)
+		}
+		return true
+	})
+
+	if !h.allow(a) {
+		return
+	}
+	if m.Level == zerolog.FatalLevel || m.Level == zerolog.PanicLevel {
+		// the program terminates right after the event is written
+		h.post(a)
+		return
+	}
+	h.closeMu.RLock()
+	defer h.closeMu.RUnlock()
+	if h.closed {
+		h.report(fmt.Errorf("alert: hook closed, alert dropped: %s", a.Message))
+		return
+	}
+	select {
+	case h.queue <- a:
+	default:
+		h.report(fmt.Errorf("alert: queue full, alert dropped: %s", a.Message))
+	}
+}
+
+// Close stops queueing alerts and waits until the queued ones are sent or ctx is done.
+// Alerts of events logged after Close are dropped.
+func (h *Hook) Close(ctx context.Context) error {
+	h.closeMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.closeMu.Unlock()
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// allow applies the deduplication window and the rate limit
+func (h *Hook) allow(a Alert) bool {
+	hash := fnv.New64a()
+	hash.Write([]byte(a.Level + "\x00" + a.Message + "\x00" + a.Error))
+	key := hash.Sum64()
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if last, ok := h.seen[key]; ok && now.Sub(last) < h.cfg.dedupWindow {
+		return false
+	}
+	if now.Sub(h.windowStart) >= h.cfg.rateInterval {
+		h.windowStart, h.sent = now, 0
+		for k, t := range h.seen {
+			if now.Sub(t) >= h.cfg.dedupWindow {
+				delete(h.seen, k)
+			}
+		}
+	}
+	if h.sent >= h.cfg.rateLimit {
+		return false
+	}
+	h.sent++
+	h.seen[key] = now
+	return true
+}
+
+func (h *Hook) run() {
+	defer close(h.done)
+	for a := range h.queue {
+		h.post(a)
+	}
+}
+
+// post renders and sends an alert
+func (h *Hook) post(a Alert) {
+	var text strings.Builder
+	if err := h.tmpl.Execute(&text, a); err != nil {
+		h.report(err)
+		return
+	}
+
+	var payload interface{}
+	switch h.cfg.format {
+	case Slack:
+		payload = map[string]string{"text": text.String()}
+	case Teams:
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  a.Message,
+			"text":     text.String(),
+		}
+	default:
+		payload = struct {
+			Text string `json:"text"`
+			Alert
+		}{text.String(), a}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		h.report(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		h.report(err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.cfg.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.cfg.client.Do(req)
+	if err != nil {
+		h.report(err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		h.report(fmt.Errorf("alert: webhook responded with status %d", resp.StatusCode))
+	}
+}
+
+func (h *Hook) report(err error) {
+	if h.cfg.onError != nil {
+		h.cfg.onError(err)
+	}
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("alert", err)
+}
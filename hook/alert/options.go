@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	format       Format
+	template     string
+	minLevel     zerolog.Level
+	rateLimit    int
+	rateInterval time.Duration
+	dedupWindow  time.Duration
+	client       *http.Client
+	headers      map[string]string
+	timeout      time.Duration
+	onError      func(error)
+}
+
+// WithFormat selects the payload format. Default is Slack.
+func WithFormat(format Format) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.format = format
+	})
+}
+
+// WithTemplate sets the text/template rendering the alert text from an Alert.
+// Default is DefaultTemplate.
+func WithTemplate(tmpl string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.template = tmpl
+	})
+}
+
+// WithMinLevel alerts on events at or above level, up to panic. Default is error.
+func WithMinLevel(level zerolog.Level) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.minLevel = level
+	})
+}
+
+// WithRateLimit posts at most limit alerts per interval. Default is 10 per minute.
+func WithRateLimit(limit int, interval time.Duration) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.rateLimit = limit
+		cfg.rateInterval = interval
+	})
+}
+
+// WithDeduplication suppresses alerts with the same level, message and error for window.
+// Default is 5 minutes.
+func WithDeduplication(window time.Duration) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.dedupWindow = window
+	})
+}
+
+// WithHTTPClient sets the client posting alerts.
+func WithHTTPClient(client *http.Client) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.client = client
+	})
+}
+
+// WithHeader adds an HTTP header to the posted requests.
+func WithHeader(key, value string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.headers[key] = value
+	})
+}
+
+// WithTimeout bounds a single post. Default is 5 seconds.
+func WithTimeout(timeout time.Duration) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.timeout = timeout
+	})
+}
+
+// WithErrorHandler is called with delivery errors. Default is the handler set with
+// logger.SetSinkErrorHandler.
+func WithErrorHandler(fn func(error)) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		format:       Slack,
+		template:     DefaultTemplate,
+		minLevel:     zerolog.ErrorLevel,
+		rateLimit:    10,
+		rateInterval: time.Minute,
+		dedupWindow:  5 * time.Minute,
+		client:       http.DefaultClient,
+		headers:      make(map[string]string),
+		timeout:      5 * time.Second,
+		onError:      defaultErrorHandler,
+	}
+}
@@ -0,0 +1,144 @@
+// Package health derives a health signal from the rate of error events, so orchestrators
+// can act on error storms without a metrics stack.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Status is the health derived from the error rate.
+type Status int
+
+const (
+	OK Status = iota
+	Degraded
+)
+
+func (s Status) String() string {
+	if s == Degraded {
+		return "degraded"
+	}
+	return "ok"
+}
+
+// buckets is the resolution of the sliding window
+const buckets = 60
+
+// Hook counts error, fatal and panic events over a sliding window and reports Degraded
+// while their number reaches the threshold. Serve Hook.Handler as a health endpoint:
+//
+//	h := health.NewHook(health.WithThreshold(100, time.Minute))
+//	logger.SetLogger(logger.Logger.Hook(h))
+//	http.Handle("/healthz/logs", h.Handler())
+type Hook struct {
+	cfg   config
+	width time.Duration
+	mu    sync.Mutex
+	// notifyMu delivers the status changes one at a time and in order
+	notifyMu sync.Mutex
+	counts   [buckets]int
+	epochs   [buckets]int64
+	status   Status
+}
+
+// NewHook creates a Hook.
+func NewHook(opts ...HookOption) *Hook {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	width := cfg.window / buckets
+	if width <= 0 {
+		width = 1
+	}
+	return &Hook{cfg: cfg, width: width}
+}
+
+func (h *Hook) Run(e *zerolog.Event, level zerolog.Level, message string) {
+	if level < h.cfg.minLevel || level > zerolog.PanicLevel {
+		return
+	}
+	epoch := time.Now().UnixNano() / int64(h.width)
+	h.mu.Lock()
+	i := epoch % buckets
+	if h.epochs[i] != epoch {
+		h.epochs[i], h.counts[i] = epoch, 0
+	}
+	h.counts[i]++
+	h.unlockNotify(h.update(epoch))
+}
+
+// Count returns the number of events within the window.
+func (h *Hook) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count(time.Now().UnixNano() / int64(h.width))
+}
+
+// Status returns the current status.
+func (h *Hook) Status() Status {
+	h.mu.Lock()
+	changed, status := h.update(time.Now().UnixNano() / int64(h.width))
+	h.unlockNotify(changed, status)
+	return status
+}
+
+// Handler returns an http.Handler answering 200 while OK and 503 while Degraded, with the
+// status, the number of events and the window as JSON.
+func (h *Hook) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := h.Status()
+		code := http.StatusOK
+		if status == Degraded {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(struct {
+			Status string `json:"status"`
+			Events int    `json:"events"`
+			Window string `json:"window"`
+		}{status.String(), h.Count(), h.cfg.window.String()})
+	})
+}
+
+// count sums the buckets of the window ending at epoch
+func (h *Hook) count(epoch int64) int {
+	var n int
+	for i := range h.counts {
+		if epoch-h.epochs[i] < buckets {
+			n += h.counts[i]
+		}
+	}
+	return n
+}
+
+// update recomputes the status and reports whether it changed
+func (h *Hook) update(epoch int64) (bool, Status) {
+	status := OK
+	if h.count(epoch) >= h.cfg.threshold {
+		status = Degraded
+	}
+	changed := status != h.status
+	h.status = status
+	return changed, status
+}
+
+// unlockNotify releases mu and calls the change callback if the status changed. notifyMu is
+// taken before mu is released, so a later change waits for the callback of this one.
+func (h *Hook) unlockNotify(changed bool, status Status) {
+	if !changed || h.cfg.onChange == nil {
+		h.mu.Unlock()
+		return
+	}
+	h.notifyMu.Lock()
+	defer h.notifyMu.Unlock()
+	h.mu.Unlock()
+	h.cfg.onChange(status)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	minLevel  zerolog.Level
+	threshold int
+	window    time.Duration
+	onChange  func(Status)
+}
+
+// WithMinLevel counts events at or above level, up to panic. Default is error.
+func WithMinLevel(level zerolog.Level) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.minLevel = level
+	})
+}
+
+// WithThreshold reports Degraded once count events are seen within window. Default is 50
+// events per minute.
+func WithThreshold(count int, window time.Duration) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.threshold = count
+		cfg.window = window
+	})
+}
+
+// WithOnChange is called with the new status when it changes. It is called synchronously
+// from the logging goroutine or the caller of Status, one change at a time and in order, so
+// it should return quickly.
+func WithOnChange(fn func(Status)) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onChange = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		minLevel:  zerolog.ErrorLevel,
+		threshold: 50,
+		window:    time.Minute,
+	}
+}
@@ -0,0 +1,27 @@
+module github.com/XiBao/logger/hook/metrics
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/XiBao/logger v0.1.0
+	github.com/prometheus/client_golang v1.20.5
+	github.com/rs/zerolog v1.33.0
+	github.com/tidwall/gjson v1.17.3
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"io"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// FieldRef is the field the hook marks error events with when an errors histogram is
+// configured. The paired writer removes it before the log line reaches the wrapped writer.
+const FieldRef = "_metrics_ref"
+
+// Hook counts zerolog events in Prometheus metrics.
+//
+// log_events_total{level,logger} is incremented for every event. With WithErrorHistogram
+// the numeric value of a field of error, fatal and panic events is observed as well, in the
+// unit it is logged in, which requires the writer returned by Hook.Writer to wrap the
+// output of the logger.
+//
+// With the package level logger use logger.Hook(h) to get a logger counting its events.
+type Hook struct {
+	name    string
+	events  *prometheus.CounterVec
+	errors  *prometheus.HistogramVec
+	field   string
+	carrier *common.Carrier
+}
+
+// NewHook creates the hook and registers its metrics on reg.
+func NewHook(reg prometheus.Registerer, opts ...HookOption) (*Hook, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+
+	h := &Hook{
+		name: cfg.name,
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "log_events_total",
+			Help:      "Number of log events by level and logger.",
+		}, []string{"level", "logger"}),
+	}
+	if err := reg.Register(h.events); err != nil {
+		return nil, err
+	}
+
+	if cfg.errorField != "" {
+		buckets := cfg.buckets
+		if buckets == nil {
+			buckets = durationBuckets()
+		}
+		h.field = cfg.errorField
+		h.carrier = common.NewCarrier(FieldRef)
+		h.errors = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "log_errors",
+			Help:      "Value of the " + cfg.errorField + " field of error log events, in the unit it is logged in.",
+			Buckets:   buckets,
+		}, []string{"level", "logger"})
+		if err := reg.Register(h.errors); err != nil {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+func (h *Hook) Run(event *zerolog.Event, level zerolog.Level, message string) {
+	h.events.WithLabelValues(level.String(), h.name).Inc()
+	if h.carrier != nil && level >= zerolog.ErrorLevel && level <= zerolog.PanicLevel {
+		h.carrier.Mark(event, level)
+	}
+}
+
+// Writer returns a zerolog.LevelWriter forwarding log lines to w and observing the errors
+// histogram. Without WithErrorHistogram w is returned as is.
+func (h *Hook) Writer(w io.Writer) io.Writer {
+	if h.carrier == nil {
+		return w
+	}
+	return h.carrier.Writer(w, h.observe)
+}
+
+// durationBuckets returns prometheus.DefBuckets converted to zerolog.DurationFieldUnit
+func durationBuckets() []float64 {
+	buckets := make([]float64, len(prometheus.DefBuckets))
+	scale := float64(time.Second) / float64(zerolog.DurationFieldUnit)
+	for i, b := range prometheus.DefBuckets {
+		buckets[i] = b * scale
+	}
+	return buckets
+}
+
+func (h *Hook) observe(m common.Marked, data []byte) {
+	value := gjson.GetBytes(data, common.EscapePath(h.field))
+	if value.Type != gjson.Number {
+		return
+	}
+	h.errors.WithLabelValues(m.Level.String(), h.name).Observe(value.Float())
+}
@@ -0,0 +1,48 @@
+package metrics
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	name       string
+	namespace  string
+	errorField string
+	buckets    []float64
+}
+
+// WithLoggerName sets the logger label of the metrics. Default is empty.
+func WithLoggerName(name string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.name = name
+	})
+}
+
+// WithNamespace prefixes the metric names with namespace.
+func WithNamespace(namespace string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.namespace = namespace
+	})
+}
+
+// WithErrorHistogram observes the value of field (e.g. a duration) of error, fatal and panic
+// events in the log_errors histogram, in the unit it is logged in: durations added with
+// Event.Dur are in zerolog.DurationFieldUnit, milliseconds by default. Events whose field
+// is missing or not a JSON number are not observed. Default buckets are
+// prometheus.DefBuckets, in seconds, converted to zerolog.DurationFieldUnit.
+func WithErrorHistogram(field string, buckets ...float64) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.errorField = field
+		if len(buckets) > 0 {
+			cfg.buckets = buckets
+		}
+	})
+}
+
+func newDefaultConfig() config {
+	return config{}
+}
@@ -1,35 +1,61 @@
 package otlp
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
 
+	"github.com/XiBao/logger/common"
 	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
 	"go.opentelemetry.io/otel/log"
 )
 
-// convertEvent transforms a zerolog event into an OpenTelemetry log record.
+// convertEvent transforms an encoded zerolog event into an OpenTelemetry log record.
 //
-// This function takes a zerolog event, its constants, and a message string as inputs.
+// This function takes the encoded event and its level as inputs.
 // It constructs an OpenTelemetry log record by setting the timestamp, message body,
-// severity constants, observed timestamp, and any additional attributes extracted from the event.
+// severity level, observed timestamp, and any additional attributes extracted from the event.
 //
 // Parameters:
-// - e *zerolog.Event: The zerolog event to be converted.
-// - constants zerolog.Level: The logging constants of the event.
-// - msg string: The log message.
+// - data []byte: The encoded zerolog event to be converted.
+// - level zerolog.Level: The logging level of the event.
 //
 // Returns:
 // - log.Record: The constructed OpenTelemetry log record.
-func (h Hook) convertEvent(e *zerolog.Event, level zerolog.Level, msg string) log.Record {
+func (h Hook) convertEvent(data []byte, level zerolog.Level) log.Record {
+	record := newRecord(level)
+
+	kvs := make([]log.KeyValue, 0)
+	gjson.ParseBytes(data).ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.LevelFieldName:
+		case zerolog.MessageFieldName:
+			record.SetBody(log.StringValue(value.String())) // Set the log message body.
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				record.SetTimestamp(t)
+			}
+		default:
+			kvs = append(kvs, log.KeyValue{
+				Key:   key.String(),
+				Value: convertValue(common.FieldValue(value)),
+			})
+		}
+		return true
+	})
+	record.AddAttributes(kvs...) // Add the remaining fields as attributes.
+	return record
+}
+
+// newRecord returns an OpenTelemetry log record of level, observed and timestamped now.
+func newRecord(level zerolog.Level) log.Record {
 	var record log.Record
-	record.SetTimestamp(time.Now().UTC())      // Set the timestamp using zerolog's configured function.
-	record.SetBody(log.StringValue(msg))       // Set the log message body.
-	record.SetSeverity(convertSeverity(level)) // Convert and set the severity constants based on zerolog's constants.
-	record.SetSeverityText(level.String())     // Set the severity text using zerolog's constants string.
-	record.AddAttributes(convertFields(e)...)  // Convert and add any additional fields  attributes.
+	now := time.Now().UTC()
+	record.SetTimestamp(now)                   // Overridden by the event timestamp, if any.
+	record.SetObservedTimestamp(now)           // Set the time the event was observed.
+	record.SetSeverity(convertSeverity(level)) // Convert and set the severity level based on zerolog's level.
+	record.SetSeverityText(level.String())     // Set the severity text using zerolog's level string.
 	return record
 }
 
@@ -69,36 +95,6 @@ func convertSeverity(level zerolog.Level) log.Severity {
 	return log.SeverityUndefined
 }
 
-// convertFields extracts and converts zerolog event fields to OpenTelemetry key-value pairs.
-//
-// This function iterates over all fields present in a zerolog event, converting each field
-// to an OpenTelemetry log.KeyValue structure. The conversion process is handled by the
-// convertValue function, which adapts the field's value to the appropriate OpenTelemetry
-// log.Value type based on the value's underlying type.
-//
-// Parameters:
-// - e *zerolog.Event: The zerolog event containing the fields to be converted.
-//
-// Returns:
-// - []log.KeyValue: A slice of OpenTelemetry key-value pairs representing the converted fields.
-func convertFields(e *zerolog.Event) []log.KeyValue {
-	ev := fmt.Sprintf("%s}", reflect.ValueOf(e).Elem().FieldByName("buf"))
-	data := make(map[string]interface{})
-	if err := json.Unmarshal([]byte(ev), &data); err != nil {
-		return nil
-	}
-
-	kvs := make([]log.KeyValue, 0)
-	for k, v := range data {
-		kvs = append(kvs, log.KeyValue{
-			Key:   k,
-			Value: convertValue(v),
-		})
-	}
-
-	return kvs
-}
-
 // convertValue adapts a generic interface value to a specific OpenTelemetry log value type.
 //
 // This function takes a value of type interface{} and determines its actual type to convert it
@@ -0,0 +1,25 @@
+module github.com/XiBao/logger/hook/otel
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/XiBao/logger v0.1.0
+	github.com/rs/zerolog v1.33.0
+	github.com/tidwall/gjson v1.17.3
+	go.opentelemetry.io/otel/log v0.6.0
+	go.opentelemetry.io/otel/trace v1.30.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.30.0 // indirect
+	go.opentelemetry.io/otel/metric v1.30.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
@@ -19,34 +19,119 @@
 package otlp
 
 import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
 )
 
 const (
 	instrumentName = "github.com/XiBao/logger/hook/otel"
+
+	// FieldRef is the field the hook marks emitted events with. The paired writer removes it
+	// before the log line reaches the wrapped writer.
+	FieldRef = "_otel_ref"
 )
 
+// errNoWriter is reported once when events are emitted without the writer of the hook
+var errNoWriter = errors.New("hook used without Hook.Writer: events are emitted without their fields")
+
+// noWriter reports errNoWriter once
+var noWriter sync.Once
+
 // Hook struct defines a logger hook for the zerolog logging library.
-type Hook struct{}
+//
+// The hook only marks events and remembers their context; the fields are read from the
+// encoded log line by the writer returned by Hook.Writer, which must wrap the output of the logger:
+//
+//	h := otlp.NewHook()
+//	log := zerolog.New(h.Writer(os.Stderr)).Hook(h)
+//
+// A hook added with Logger.Hook alone, as in earlier versions, still emits records, but
+// only with the level and message of the events: it cannot read their fields without its
+// writer. The first such record is reported to the sink error handler, see
+// logger.SetSinkErrorHandler. The zero Hook emits every event through the global logger
+// provider, like NewHook().
+//
+// To migrate from log.Hook(otlp.NewHook()), route the output of the logger through the
+// writer of the hook:
+//
+//	h := otlp.NewHook()
+//	log = log.Output(h.Writer(out)).Hook(h)
+type Hook struct {
+	provider log.LoggerProvider
+	name     string
+	minLevel zerolog.Level
+	carrier  *common.Carrier
+}
+
+// NewHook creates a hook emitting records through the global logger provider unless
+// WithLoggerProvider is given.
+func NewHook(opts ...HookOption) *Hook {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Hook{
+		provider: cfg.provider,
+		name:     cfg.name,
+		minLevel: cfg.minLevel,
+		carrier:  common.NewCarrier(FieldRef),
+	}
+}
 
 // Run is the method that gets called on each log event.
-// It converts the zerolog event to an OpenTelemetry log record and emits it using the global logger provider.
-// In case of PanicLevel or FatalLevel log events, it also attempts to shut down the logger provider gracefully.
+// It marks events at or above the minimum level, so the writer returned by Hook.Writer
+// converts them to OpenTelemetry log records once they are encoded. Without the writer,
+// it emits a record of the level and message right away.
 //
 // Parameters:
 // - event: The zerolog event that contains all the log information.
-// - constants: The logging constants of the event (e.g., Info, Warn, Error).
+// - level: The logging level of the event (e.g., Info, Warn, Error).
 // - message: The log message.
-//
-// The method extracts the context from the event, converts the event to an OpenTelemetry log record,
-// and emits the record using the global logger provider. If the log constants is PanicLevel or FatalLevel,
-// it shuts down the logger provider to ensure all logs are flushed before the application exits.
 func (h Hook) Run(event *zerolog.Event, level zerolog.Level, message string) {
-	ctx := event.GetCtx()
+	// the zero Hook, without a carrier, has no minimum level
+	if h.carrier != nil && level < h.minLevel && level != zerolog.NoLevel {
+		return
+	}
+	if !h.carrier.Attached() {
+		noWriter.Do(func() { sinkerr.Handle("otel", errNoWriter) })
+		record := newRecord(level)
+		record.SetBody(log.StringValue(message))
+		h.logger().Emit(event.GetCtx(), record)
+		return
+	}
+	h.carrier.Mark(event, level)
+}
+
+// Writer returns a zerolog.LevelWriter forwarding log lines to w and emitting the events
+// marked by the hook.
+func (h Hook) Writer(w io.Writer) zerolog.LevelWriter {
+	return h.carrier.Writer(w, h.emit)
+}
+
+// emit converts the encoded log line to an OpenTelemetry log record and emits it with the
+// event context, so the record is correlated with the active span.
+func (h Hook) emit(m common.Marked, data []byte) {
+	record := h.convertEvent(data, m.Level) // Convert zerolog event to OpenTelemetry log record.
+	h.logger().Emit(m.Ctx, record)          // Emit the log record.
+}
 
-	// Extract context from the event.
-	record := h.convertEvent(event, level, message)   // Convert zerolog event to OpenTelemetry log record.
-	provider := global.GetLoggerProvider()            // Get the global logger provider.
-	provider.Logger(instrumentName).Emit(ctx, record) // Emit the log record.
+// logger returns the OpenTelemetry logger of the hook, from the global logger provider
+// unless WithLoggerProvider was given.
+func (h Hook) logger() log.Logger {
+	provider := h.provider
+	if provider == nil {
+		provider = global.GetLoggerProvider() // Get the global logger provider.
+	}
+	name := h.name
+	if name == "" {
+		name = instrumentName
+	}
+	return provider.Logger(name)
 }
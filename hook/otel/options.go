@@ -0,0 +1,48 @@
+package otlp
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/log"
+)
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	provider log.LoggerProvider
+	name     string
+	minLevel zerolog.Level
+}
+
+// WithLoggerProvider emits records through provider instead of the global logger provider.
+func WithLoggerProvider(provider log.LoggerProvider) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.provider = provider
+	})
+}
+
+// WithInstrumentationName sets the name of the OpenTelemetry logger. Default is the import path of the hook.
+func WithInstrumentationName(name string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.name = name
+	})
+}
+
+// WithMinLevel only emits events at or above level. Default is trace.
+func WithMinLevel(level zerolog.Level) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.minLevel = level
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		name:     instrumentName,
+		minLevel: zerolog.TraceLevel,
+	}
+}
@@ -0,0 +1,63 @@
+package otlp
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type verboseKey struct{}
+
+// WithVerbose returns a copy of ctx whose events pass a SampledHook as if its trace was
+// sampled.
+func WithVerbose(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verboseKey{}, true)
+}
+
+// Verbose reports whether the events of ctx pass a SampledHook: the span of ctx is sampled
+// or ctx was returned by WithVerbose.
+func Verbose(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if v, _ := ctx.Value(verboseKey{}).(bool); v {
+		return true
+	}
+	return trace.SpanContextFromContext(ctx).IsSampled()
+}
+
+// SampledHook discards the events below a level unless their context is Verbose, giving
+// detailed logs for sampled requests only. Events get their context from Event.Ctx or
+// Context.Ctx of zerolog.
+type SampledHook struct {
+	below zerolog.Level
+}
+
+// NewSampledHook creates a hook discarding the events below level, usually the level of
+// the logger before it was lowered to debug, unless their context is Verbose.
+func NewSampledHook(level zerolog.Level) SampledHook {
+	return SampledHook{below: level}
+}
+
+func (h SampledHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level >= h.below || level == zerolog.NoLevel {
+		return
+	}
+	if !Verbose(e.GetCtx()) {
+		e.Discard()
+	}
+}
+
+// TraceSampled returns l logging debug events too when their context is Verbose, e.g. for
+// the requests of sampled traces; other events are logged at the level of l.
+//
+//	l = otlp.TraceSampled(l)
+//	l.Debug().Ctx(r.Context()).Msg("cache miss")
+func TraceSampled(l zerolog.Logger) zerolog.Logger {
+	level := l.GetLevel()
+	if level <= zerolog.DebugLevel {
+		return l
+	}
+	return l.Level(zerolog.DebugLevel).Hook(NewSampledHook(level))
+}
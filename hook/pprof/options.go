@@ -0,0 +1,34 @@
+package pprof
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	key    string
+	labels []string
+}
+
+// WithLabelKey sets the label holding the component. Default is component.
+func WithLabelKey(key string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.key = key
+	})
+}
+
+// WithLabel adds a constant label, e.g. the subsystem of the component.
+func WithLabel(key, value string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.labels = append(cfg.labels, key, value)
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		key: "component",
+	}
+}
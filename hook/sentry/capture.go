@@ -0,0 +1,65 @@
+package sentry
+
+import (
+	"context"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sentryutil"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FieldCapture is the reserved field marking events captured with CaptureErr.
+const FieldCapture = common.FieldCapture
+
+type captureKey struct{}
+
+// CaptureErr adds err and the stack of the caller to e, asks for the event to be captured
+// to Sentry whatever its level, and records err on the span of the event context, if any.
+// A nil err leaves e unchanged.
+//
+//	sentry.CaptureErr(logger.Ctx(ctx).Warn().Ctx(ctx), err).Msg("retrying")
+//
+// The Hook sees the request through the event context, set with Event.Ctx before calling
+// CaptureErr, and the Sentry writer through the FieldCapture field.
+func CaptureErr(e *zerolog.Event, err error) *zerolog.Event {
+	if err == nil || !e.Enabled() {
+		return e
+	}
+	ctx := e.GetCtx()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return e.Ctx(context.WithValue(ctx, captureKey{}, true)).
+		Err(err).
+		Interface(zerolog.ErrorStackFieldName, sentryutil.ErrWithStackTrace{
+			Stacktrace: callerStacktrace(),
+			Err:        err.Error(),
+		}).
+		Bool(FieldCapture, true)
+}
+
+// captureRequested reports whether the event context was set by CaptureErr
+func captureRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(captureKey{}).(bool)
+	return requested
+}
+
+// callerStacktrace returns the stack of the caller of CaptureErr
+func callerStacktrace() *sentry.Stacktrace {
+	const pkg = "github.com/XiBao/logger/hook/sentry"
+	st := sentry.NewStacktrace()
+	n := len(st.Frames)
+	for n > 0 && st.Frames[n-1].Module == pkg &&
+		(st.Frames[n-1].Function == "callerStacktrace" || st.Frames[n-1].Function == "CaptureErr") {
+		n--
+	}
+	st.Frames = st.Frames[:n]
+	return st
+}
@@ -0,0 +1,24 @@
+module github.com/XiBao/logger/hook/sentry
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/XiBao/logger v0.1.0
+	github.com/XiBao/logger/internal/sentryutil v0.1.0
+	github.com/getsentry/sentry-go v0.29.0
+	github.com/rs/zerolog v1.33.0
+	github.com/tidwall/gjson v1.17.3
+	go.opentelemetry.io/otel v1.30.0
+	go.opentelemetry.io/otel/trace v1.30.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/text v0.18.0 // indirect
+)
@@ -1,8 +1,15 @@
 package sentry
 
 import (
+	"context"
+	"errors"
+	"io"
+	"sync"
 	"time"
 
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sentryutil"
+	"github.com/XiBao/logger/internal/sinkerr"
 	"github.com/getsentry/sentry-go"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/codes"
@@ -11,34 +18,153 @@ import (
 
 const FlushTimeout = 2 * time.Second
 
-type Hook struct{}
+// FieldRef is the field the hook marks captured events with. The paired writer removes it
+// before the log line reaches the wrapped writer.
+const FieldRef = "_sentry_ref"
 
-func NewHook() *Hook {
-	return new(Hook)
+// Hook captures zerolog events to Sentry. The hook only marks events and remembers their
+// context; the fields are read from the encoded log line by the writer returned by
+// Hook.Writer, which must wrap the output of the logger:
+//
+//	h := sentry.NewHook()
+//	log := zerolog.New(h.Writer(os.Stderr)).Hook(h)
+//
+// A hook added with Logger.Hook alone, as in earlier versions, still captures events, but
+// only with their level and message: it cannot read their fields and errors without its
+// writer. The first such capture is reported to the sink error handler, see
+// logger.SetSinkErrorHandler. The zero Hook captures error events like NewHook().
+//
+// To migrate from log.Hook(sentry.NewHook()), route the output of the logger through the
+// writer of the hook:
+//
+//	h := sentry.NewHook()
+//	log = log.Output(h.Writer(out)).Hook(h)
+type Hook struct {
+	levels       map[zerolog.Level]struct{}
+	flushTimeout time.Duration
+	tagFields    []string
+	userFields   common.UserFields
+	carrier      *common.Carrier
 }
 
+func NewHook(opts ...HookOption) *Hook {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+
+	levels := make(map[zerolog.Level]struct{}, len(cfg.levels))
+	for _, lvl := range cfg.levels {
+		levels[lvl] = struct{}{}
+	}
+
+	return &Hook{
+		levels:       levels,
+		flushTimeout: cfg.flushTimeout,
+		tagFields:    cfg.tagFields,
+		userFields:   cfg.userFields,
+		carrier:      common.NewCarrier(FieldRef),
+	}
+}
+
+// errNoWriter is reported once when events are captured without the writer of the hook
+var errNoWriter = errors.New("hook used without Hook.Writer: events are captured without their fields and errors")
+
+// noWriter reports errNoWriter once
+var noWriter sync.Once
+
 func (h Hook) Run(event *zerolog.Event, level zerolog.Level, message string) {
-	if level == zerolog.ErrorLevel {
-		ctx := event.GetCtx()
-		captured, err := h.convertEvent(event, level, message)
-		hub := sentry.GetHubFromContext(ctx)
-		if hub == nil {
-			hub = sentry.CurrentHub().Clone()
-		}
-		if client, scope := hub.Client(), hub.Scope(); client != nil {
-			client.CaptureEvent(&captured, &sentry.EventHint{Context: ctx}, scope)
+	if ctx := event.GetCtx(); h.enabled(level) || captureRequested(ctx) {
+		if !h.carrier.Attached() {
+			noWriter.Do(func() { sinkerr.Handle("sentry", errNoWriter) })
+			h.send(ctx, level, sentry.Event{
+				Level:     sentryutil.LevelsMapping[level],
+				Message:   message,
+				Timestamp: zerolog.TimestampFunc(),
+			})
 			return
 		}
-		hub.CaptureEvent(&captured)
-		if err != nil {
-			if span := trace.SpanFromContext(ctx); span.IsRecording() {
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
-			}
+		// captured fatal and panic events are flushed by the writer
+		h.carrier.Mark(event, level)
+		return
+	}
+
+	if isFinal(level) {
+		sentry.Flush(h.timeout())
+	}
+}
+
+// enabled reports whether events of level are captured, error events for the zero Hook
+func (h Hook) enabled(level zerolog.Level) bool {
+	if h.levels == nil {
+		return level == zerolog.ErrorLevel
+	}
+	_, ok := h.levels[level]
+	return ok
+}
+
+// timeout returns the flush timeout, FlushTimeout for the zero Hook
+func (h Hook) timeout() time.Duration {
+	if h.levels == nil {
+		return FlushTimeout
+	}
+	return h.flushTimeout
+}
+
+// Writer returns a zerolog.LevelWriter forwarding log lines to w and capturing the events
+// marked by the hook.
+func (h Hook) Writer(w io.Writer) zerolog.LevelWriter {
+	return h.carrier.Writer(w, h.capture)
+}
+
+// isFinal reports whether the program terminates after an event of level
+func isFinal(level zerolog.Level) bool {
+	return level == zerolog.FatalLevel || level == zerolog.PanicLevel
+}
+
+// capture sends the encoded log line of a marked event to Sentry
+func (h Hook) capture(m common.Marked, data []byte) {
+	ctx := m.Ctx
+	captured, err := h.convertEvent(data, m.Level)
+	// errors of CaptureErr are already recorded on the span
+	if err != nil && !captureRequested(ctx) {
+		if span := trace.SpanFromContext(ctx); span.IsRecording() {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 		}
 	}
+	h.send(ctx, m.Level, captured)
+}
+
+// send captures event with the hub of ctx, linked to its OpenTelemetry span, and flushes it
+// for fatal and panic events
+func (h Hook) send(ctx context.Context, level zerolog.Level, captured sentry.Event) {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub().Clone()
+	}
+	if client, scope := hub.Client(), hub.Scope(); client != nil {
+		client.CaptureEvent(&captured, &sentry.EventHint{Context: ctx}, traceScope(ctx, scope))
+	} else {
+		hub.CaptureEvent(&captured)
+	}
+	if isFinal(level) {
+		hub.Flush(h.timeout())
+	}
+}
 
-	if level == zerolog.FatalLevel || level == zerolog.PanicLevel {
-		sentry.Flush(FlushTimeout)
+// traceScope links the event to the OpenTelemetry span of ctx, if any. The scope sets the
+// trace context and the dynamic sampling context of the event, so its propagation context is
+// replaced on a copy. A Sentry span on the scope takes precedence.
+func traceScope(ctx context.Context, scope *sentry.Scope) *sentry.Scope {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return scope
 	}
+	scope = scope.Clone()
+	scope.SetPropagationContext(sentry.PropagationContext{
+		TraceID: sentry.TraceID(sc.TraceID()),
+		SpanID:  sentry.SpanID(sc.SpanID()),
+	})
+	return scope
 }
@@ -0,0 +1,138 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+// recordingTransport keeps the events captured by a client
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool       { return true }
+func (t *recordingTransport) Configure(sentry.ClientOptions) {}
+
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	t.events = append(t.events, event)
+	t.mu.Unlock()
+}
+
+// hubContext returns a context carrying a hub which records its events in transport
+func hubContext(tb testing.TB, transport sentry.Transport) context.Context {
+	tb.Helper()
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.invalid/1", Transport: transport})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return sentry.SetHubOnContext(context.Background(), sentry.NewHub(client, sentry.NewScope()))
+}
+
+func TestHookCapture(t *testing.T) {
+	transport := new(recordingTransport)
+	ctx := hubContext(t, transport)
+	h := NewHook()
+	log := zerolog.New(h.Writer(io.Discard)).Hook(h)
+
+	log.Warn().Ctx(ctx).Str("k", "v").Msg("ignored")
+	log.Error().Ctx(ctx).Err(errors.New("boom")).Str("k", "v").Msg("failed")
+
+	if len(transport.events) != 1 {
+		t.Fatalf("captured %d events, want 1", len(transport.events))
+	}
+	event := transport.events[0]
+	if event.Message != "failed" || event.Level != sentry.LevelError {
+		t.Errorf("captured %q at %q", event.Message, event.Level)
+	}
+	if event.Extra["k"] != "v" {
+		t.Errorf("extra k = %v, want v", event.Extra["k"])
+	}
+	if _, ok := event.Extra[FieldRef]; ok {
+		t.Errorf("extras carry %s", FieldRef)
+	}
+}
+
+func TestHookWithoutWriter(t *testing.T) {
+	var reported []error
+	sinkerr.SetHandler(func(sink string, err error) { reported = append(reported, err) })
+	defer sinkerr.SetHandler(nil)
+
+	transport := new(recordingTransport)
+	ctx := hubContext(t, transport)
+	var out bytes.Buffer
+	for _, h := range []zerolog.Hook{NewHook(), Hook{}} {
+		log := zerolog.New(&out).Hook(h)
+		log.Warn().Ctx(ctx).Msg("ignored")
+		log.Error().Ctx(ctx).Str("k", "v").Msg("failed")
+	}
+
+	if want := `{"level":"error","k":"v","message":"failed"}` + "\n"; strings.Count(out.String(), want) != 2 {
+		t.Errorf("got %s", out.String())
+	}
+	if len(transport.events) != 2 {
+		t.Fatalf("captured %d events, want 2", len(transport.events))
+	}
+	for _, event := range transport.events {
+		if event.Message != "failed" || event.Level != sentry.LevelError {
+			t.Errorf("captured %q at %q", event.Message, event.Level)
+		}
+	}
+	if len(reported) != 1 || reported[0] != errNoWriter {
+		t.Errorf("reported %v, want errNoWriter once", reported)
+	}
+}
+
+func BenchmarkHookNotCaptured(b *testing.B) {
+	h := NewHook()
+	log := zerolog.New(h.Writer(io.Discard)).Hook(h)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info().Str("k", "v").Int("n", i).Msg("message")
+	}
+}
+
+func BenchmarkHookCapture(b *testing.B) {
+	ctx := hubContext(b, new(discardTransport))
+	h := NewHook()
+	log := zerolog.New(h.Writer(io.Discard)).Hook(h)
+	err := errors.New("boom")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Error().Ctx(ctx).Err(err).Str("k", "v").Int("n", i).Msg("message")
+	}
+}
+
+func BenchmarkHookCaptureParallel(b *testing.B) {
+	ctx := hubContext(b, new(discardTransport))
+	h := NewHook()
+	log := zerolog.New(h.Writer(io.Discard)).Hook(h)
+	err := errors.New("boom")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Error().Ctx(ctx).Err(err).Str("k", "v").Msg("message")
+		}
+	})
+}
+
+// discardTransport drops the events captured by a client
+type discardTransport struct{}
+
+func (discardTransport) Flush(time.Duration) bool       { return true }
+func (discardTransport) Configure(sentry.ClientOptions) {}
+func (discardTransport) SendEvent(*sentry.Event)        {}
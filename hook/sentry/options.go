@@ -0,0 +1,59 @@
+package sentry
+
+import (
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/rs/zerolog"
+)
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	levels       []zerolog.Level
+	flushTimeout time.Duration
+	tagFields    []string
+	userFields   common.UserFields
+}
+
+// WithLevels configures zerolog levels that have to be captured. Default level is error.
+func WithLevels(levels ...zerolog.Level) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.levels = levels
+	})
+}
+
+// WithFlushTimeout sets how long fatal and panic events wait for buffered events to be sent.
+// Default is FlushTimeout.
+func WithFlushTimeout(timeout time.Duration) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.flushTimeout = timeout
+	})
+}
+
+// WithTagFields sends the values of the given fields as indexed Sentry tags.
+func WithTagFields(fields ...string) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.tagFields = append(cfg.tagFields, fields...)
+	})
+}
+
+// WithUserFields maps log fields to the Sentry user of the event.
+func WithUserFields(fields common.UserFields) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.userFields = fields
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		levels:       []zerolog.Level{zerolog.ErrorLevel},
+		flushTimeout: FlushTimeout,
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// Humanize enables the human-readable siblings of ByteSize, HumanDuration and HumanCount.
+	// Set it to false to write the raw values only.
+	Humanize = true
+
+	// HumanSuffix is appended to the key of the human-readable sibling.
+	HumanSuffix = "_hr"
+)
+
+// humanField is a numeric field with a human-readable sibling
+type humanField struct {
+	key   string
+	add   func(e *zerolog.Event, key string)
+	human func() string
+}
+
+func (f humanField) MarshalZerologObject(e *zerolog.Event) {
+	f.add(e, f.key)
+	if Humanize {
+		e.Str(f.key+HumanSuffix, f.human())
+	}
+}
+
+// ByteSize returns the fields key=bytes and key_hr with the size in IEC units, e.g.
+// size=1048576 size_hr="1.0 MiB". Add them with Event.EmbedObject or Context.EmbedObject:
+//
+//	logger.Info().EmbedObject(logger.ByteSize("size", n)).Msg("uploaded")
+func ByteSize(key string, bytes int64) zerolog.LogObjectMarshaler {
+	return humanField{
+		key: key,
+		add: func(e *zerolog.Event, key string) { e.Int64(key, bytes) },
+		human: func() string {
+			return humanBytes(bytes)
+		},
+	}
+}
+
+// HumanDuration returns the fields key, encoded as by Event.Dur, and key_hr as written by
+// time.Duration.String, e.g. latency=1500 latency_hr="1.5s".
+func HumanDuration(key string, d time.Duration) zerolog.LogObjectMarshaler {
+	return humanField{
+		key:   key,
+		add:   func(e *zerolog.Event, key string) { e.Dur(key, d) },
+		human: d.String,
+	}
+}
+
+// HumanCount returns the fields key=n and key_hr with SI suffixes, e.g. rows=1500000
+// rows_hr="1.5M".
+func HumanCount(key string, n int64) zerolog.LogObjectMarshaler {
+	return humanField{
+		key: key,
+		add: func(e *zerolog.Event, key string) { e.Int64(key, n) },
+		human: func() string {
+			return humanCount(n)
+		},
+	}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64) + " " + "KMGTPE"[exp:exp+1] + "iB"
+}
+
+func humanCount(n int64) string {
+	const unit = 1000
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return strconv.FormatInt(n, 10)
+	}
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64) + "kMGTPE"[exp:exp+1]
+}
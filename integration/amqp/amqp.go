@@ -0,0 +1,84 @@
+// Package amqp logs the lifecycle of RabbitMQ deliveries in consumers.
+package amqp
+
+import (
+	"context"
+	"time"
+
+	"github.com/XiBao/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the delivery logs.
+const (
+	FieldExchange    = "exchange"
+	FieldRoutingKey  = "routing_key"
+	FieldDeliveryTag = "delivery_tag"
+	FieldMessageID   = "message_id"
+	FieldRedelivered = "redelivered"
+	FieldRequeue     = "requeue"
+	FieldDuration    = "duration"
+)
+
+// Delivery wraps an amqp.Delivery and logs its acknowledgement with the time spent
+// handling it: acks at Debug level, nacks and rejects at Warn level, and failures to
+// acknowledge at Error level.
+type Delivery struct {
+	amqp.Delivery
+	l     zerolog.Logger
+	start time.Time
+}
+
+// Track logs the reception of d at Debug level and returns it wrapped, with a context
+// carrying a logger with the delivery fields for the handler.
+//
+//	for d := range deliveries {
+//		ctx, d := amqplog.Track(ctx, d)
+//		if err := handle(ctx, d.Body); err != nil {
+//			d.Nack(false, true)
+//			continue
+//		}
+//		d.Ack(false)
+//	}
+func Track(ctx context.Context, d amqp.Delivery) (context.Context, *Delivery) {
+	base := zerolog.Ctx(ctx)
+	if base.GetLevel() == zerolog.Disabled {
+		base = &logger.Logger
+	}
+	c := base.With().
+		Str(FieldExchange, d.Exchange).
+		Str(FieldRoutingKey, d.RoutingKey).
+		Uint64(FieldDeliveryTag, d.DeliveryTag)
+	if d.MessageId != "" {
+		c = c.Str(FieldMessageID, d.MessageId)
+	}
+	l := c.Logger()
+	l.Debug().Bool(FieldRedelivered, d.Redelivered).Msg("amqp delivery received")
+	return l.WithContext(ctx), &Delivery{Delivery: d, l: l, start: time.Now()}
+}
+
+func (d *Delivery) Ack(multiple bool) error {
+	err := d.Delivery.Ack(multiple)
+	d.log(zerolog.DebugLevel, err).Msg("amqp delivery acked")
+	return err
+}
+
+func (d *Delivery) Nack(multiple, requeue bool) error {
+	err := d.Delivery.Nack(multiple, requeue)
+	d.log(zerolog.WarnLevel, err).Bool(FieldRequeue, requeue).Msg("amqp delivery nacked")
+	return err
+}
+
+func (d *Delivery) Reject(requeue bool) error {
+	err := d.Delivery.Reject(requeue)
+	d.log(zerolog.WarnLevel, err).Bool(FieldRequeue, requeue).Msg("amqp delivery rejected")
+	return err
+}
+
+func (d *Delivery) log(level zerolog.Level, err error) *zerolog.Event {
+	if err != nil {
+		level = zerolog.ErrorLevel
+	}
+	return d.l.WithLevel(level).Err(err).Dur(FieldDuration, time.Since(d.start))
+}
@@ -0,0 +1,130 @@
+// Package containers logs the output of containers started by integration tests, e.g. with
+// testcontainers-go or dockertest, one event per line.
+//
+// For testcontainers-go, forward the logs from a LogConsumer:
+//
+//	type consumer struct{ c *containers.Consumer }
+//
+//	func (c consumer) Accept(l testcontainers.Log) { c.c.Write(l.LogType, l.Content) }
+//
+// For dockertest and the Docker SDK, use Stdout and Stderr as the output streams.
+package containers
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Field names of the container logs.
+const (
+	FieldContainer = "container"
+	FieldStream    = "stream"
+	FieldLog       = "log"
+)
+
+// Stream names.
+const (
+	StreamStdout = "STDOUT"
+	StreamStderr = "STDERR"
+)
+
+// maxLine bounds the buffered partial line, longer lines are logged in pieces
+const maxLine = 64 * 1024
+
+// Consumer logs the lines of a container to l with the container name and the stream.
+// Lines that are JSON objects, such as the output of a zerolog logger, are kept as an
+// object in the log field; other lines are the message.
+type Consumer struct {
+	l   zerolog.Logger
+	cfg config
+
+	mu      sync.Mutex
+	partial map[string][]byte
+}
+
+// NewConsumer creates a Consumer for the container name.
+func NewConsumer(l zerolog.Logger, name string, opts ...ConsumerOption) *Consumer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	ctx := l.With().Str(FieldContainer, name)
+	for k, v := range cfg.fields {
+		ctx = ctx.Str(k, v)
+	}
+	return &Consumer{l: ctx.Logger(), cfg: cfg, partial: make(map[string][]byte)}
+}
+
+// Write logs the complete lines of p, read from stream. An incomplete last line is kept
+// until the next Write on the same stream or Flush.
+func (c *Consumer) Write(stream string, p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	buf := append(c.partial[stream], p...)
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+		c.log(stream, buf[:i])
+		buf = buf[i+1:]
+	}
+	if len(buf) > maxLine {
+		c.log(stream, buf)
+		buf = nil
+	}
+	c.partial[stream] = append(c.partial[stream][:0], buf...)
+}
+
+// Flush logs the incomplete lines kept by Write.
+func (c *Consumer) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for stream, buf := range c.partial {
+		if len(buf) > 0 {
+			c.log(stream, buf)
+		}
+		delete(c.partial, stream)
+	}
+}
+
+// Stdout returns a writer logging the lines written to it as the stdout stream.
+func (c *Consumer) Stdout() io.Writer {
+	return streamWriter{c: c, stream: StreamStdout}
+}
+
+// Stderr returns a writer logging the lines written to it as the stderr stream.
+func (c *Consumer) Stderr() io.Writer {
+	return streamWriter{c: c, stream: StreamStderr}
+}
+
+func (c *Consumer) log(stream string, line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	level := c.cfg.stdoutLevel
+	if stream == StreamStderr {
+		level = c.cfg.stderrLevel
+	}
+	e := c.l.WithLevel(level).Str(FieldStream, stream)
+	if line[0] == '{' && gjson.ValidBytes(line) {
+		e.RawJSON(FieldLog, line).Send()
+		return
+	}
+	e.Msg(string(line))
+}
+
+type streamWriter struct {
+	c      *Consumer
+	stream string
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.c.Write(w.stream, p)
+	return len(p), nil
+}
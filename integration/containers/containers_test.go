@@ -0,0 +1,82 @@
+package containers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/XiBao/logger/testutil"
+	"github.com/rs/zerolog"
+)
+
+func TestConsumerJSON(t *testing.T) {
+	w := testutil.NewCaptureWriter()
+	c := NewConsumer(zerolog.New(w), "db")
+	c.Write(StreamStdout, []byte(`{"level":"info","message":"ready","port":5432}`+"\n"))
+
+	want := `{"level":"info","container":"db","stream":"STDOUT","log":{"level":"info","message":"ready","port":5432}}`
+	if lines := w.Lines(); len(lines) != 1 || lines[0] != want {
+		t.Errorf("lines = %q, want [%s]", lines, want)
+	}
+}
+
+func TestConsumerText(t *testing.T) {
+	w := testutil.NewCaptureWriter()
+	c := NewConsumer(zerolog.New(w), "db", WithLevels(zerolog.DebugLevel, zerolog.WarnLevel), WithField("image", "postgres:16"))
+	c.Write(StreamStdout, []byte("starting\r\n\n  \n"))
+	c.Write(StreamStderr, []byte("{not json\n"))
+
+	want := []string{
+		`{"level":"debug","container":"db","image":"postgres:16","stream":"STDOUT","message":"starting"}`,
+		`{"level":"warn","container":"db","image":"postgres:16","stream":"STDERR","message":"{not json"}`,
+	}
+	if got := w.Lines(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestConsumerPartialLines(t *testing.T) {
+	w := testutil.NewCaptureWriter()
+	c := NewConsumer(zerolog.New(w), "db")
+	c.Write(StreamStdout, []byte(`{"message":`))
+	c.Write(StreamStderr, []byte("err"))
+	c.Write(StreamStdout, []byte(`"ready"}`+"\nsecond\nthi"))
+	c.Write(StreamStderr, []byte("or\n"))
+	c.Write(StreamStdout, []byte("rd"))
+	if got := w.Len(); got != 3 {
+		t.Fatalf("%d lines logged before Flush, want 3: %q", got, w.Lines())
+	}
+	c.Flush()
+
+	want := []string{
+		`{"level":"info","container":"db","stream":"STDOUT","log":{"message":"ready"}}`,
+		`{"level":"info","container":"db","stream":"STDOUT","message":"second"}`,
+		`{"level":"info","container":"db","stream":"STDERR","message":"error"}`,
+		`{"level":"info","container":"db","stream":"STDOUT","message":"third"}`,
+	}
+	if got := w.Lines(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+
+	c.Flush()
+	if got := w.Len(); got != 4 {
+		t.Errorf("%d lines after a second Flush, want 4", got)
+	}
+}
+
+func TestConsumerLongLine(t *testing.T) {
+	w := testutil.NewCaptureWriter()
+	c := NewConsumer(zerolog.New(w), "db")
+	long := strings.Repeat("x", maxLine+1)
+	c.Stdout().Write([]byte(long[:maxLine]))
+	if got := w.Len(); got != 0 {
+		t.Fatalf("%d lines logged before the line exceeds the limit, want 0", got)
+	}
+	c.Stdout().Write([]byte(long[maxLine:]))
+	if got := w.Len(); got != 1 {
+		t.Fatalf("%d lines logged after the line exceeds the limit, want 1", got)
+	}
+	if got := w.Entries()[0]["message"]; got != long {
+		t.Errorf("message of %d bytes, want %d", len(fmt.Sprint(got)), len(long))
+	}
+}
@@ -0,0 +1,42 @@
+package containers
+
+import (
+	"github.com/rs/zerolog"
+)
+
+type ConsumerOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	stdoutLevel zerolog.Level
+	stderrLevel zerolog.Level
+	fields      map[string]string
+}
+
+// WithLevels sets the level of the stdout and stderr lines. Default is Info for both.
+func WithLevels(stdout, stderr zerolog.Level) ConsumerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.stdoutLevel = stdout
+		cfg.stderrLevel = stderr
+	})
+}
+
+// WithField adds a field to every line, e.g. the image or the test name.
+func WithField(key, value string) ConsumerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.fields[key] = value
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		stdoutLevel: zerolog.InfoLevel,
+		stderrLevel: zerolog.InfoLevel,
+		fields:      make(map[string]string),
+	}
+}
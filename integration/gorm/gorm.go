@@ -0,0 +1,114 @@
+// Package gorm logs GORM statements through zerolog.
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/integration/sqllog"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+var _ = gormlogger.Interface(new(Logger))
+
+// Logger implements the GORM logger.Interface, set it as the Logger of a gorm.Config.
+// Messages and statements are logged with the logger of the statement context, or the
+// configured logger when the context has none. The caller is the application code that ran
+// the statement.
+type Logger struct {
+	cfg  config
+	mode gormlogger.LogLevel
+}
+
+// New creates a Logger in the Info mode, logging every statement.
+func New(opts ...LoggerOption) *Logger {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Logger{cfg: cfg, mode: gormlogger.Info}
+}
+
+// LogMode returns a copy of the logger with the GORM log level mode.
+func (l *Logger) LogMode(mode gormlogger.LogLevel) gormlogger.Interface {
+	nl := *l
+	nl.mode = mode
+	return &nl
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Info {
+		l.log(ctx, zerolog.InfoLevel, msg, args)
+	}
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Warn {
+		l.log(ctx, zerolog.WarnLevel, msg, args)
+	}
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Error {
+		l.log(ctx, zerolog.ErrorLevel, msg, args)
+	}
+}
+
+// Trace logs a statement: at Error level when it failed, at Warn level when slower than
+// the slow threshold, and at the statement level otherwise.
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.mode <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	var level zerolog.Level
+	switch {
+	case err != nil && l.mode >= gormlogger.Error && !(l.cfg.ignoreNotFound && errors.Is(err, gorm.ErrRecordNotFound)):
+		level = zerolog.ErrorLevel
+	case l.cfg.slow > 0 && elapsed > l.cfg.slow && l.mode >= gormlogger.Warn:
+		level = zerolog.WarnLevel
+	case l.mode >= gormlogger.Info:
+		level = l.cfg.level
+	default:
+		return
+	}
+	e := l.logger(ctx).WithLevel(level)
+	if !e.Enabled() {
+		return
+	}
+	sql, rows := fc()
+	if level != zerolog.ErrorLevel {
+		err = nil
+	}
+	e = e.Err(err).
+		Str(zerolog.CallerFieldName, utils.FileWithLineNum()).
+		Str(sqllog.FieldQuery, sql)
+	if rows >= 0 {
+		e = e.Int64(sqllog.FieldRowsAffected, rows)
+	}
+	e.Dur(sqllog.FieldDuration, elapsed).Msg("sql")
+}
+
+func (l *Logger) log(ctx context.Context, level zerolog.Level, msg string, args []interface{}) {
+	l.logger(ctx).WithLevel(level).
+		Str(zerolog.CallerFieldName, utils.FileWithLineNum()).
+		Msg(fmt.Sprintf(msg, args...))
+}
+
+func (l *Logger) logger(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if zl := zerolog.Ctx(ctx); zl.GetLevel() != zerolog.Disabled {
+			return zl
+		}
+	}
+	if l.cfg.logger != nil {
+		return l.cfg.logger
+	}
+	return &logger.Logger
+}
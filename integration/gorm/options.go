@@ -0,0 +1,59 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type LoggerOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger         *zerolog.Logger
+	level          zerolog.Level
+	slow           time.Duration
+	ignoreNotFound bool
+}
+
+// WithLogger sets the logger used for statement contexts without a logger. Default is the
+// global logger.Logger.
+func WithLogger(l zerolog.Logger) LoggerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithLevel sets the level of successful statements. Default is Debug.
+func WithLevel(level zerolog.Level) LoggerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = level
+	})
+}
+
+// WithSlowThreshold logs statements slower than threshold at Warn level, 0 disables it.
+// Default is 200ms, as GORM's own logger.
+func WithSlowThreshold(threshold time.Duration) LoggerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.slow = threshold
+	})
+}
+
+// WithIgnoreRecordNotFound does not log gorm.ErrRecordNotFound as an error.
+func WithIgnoreRecordNotFound() LoggerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.ignoreNotFound = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level: zerolog.DebugLevel,
+		slow:  200 * time.Millisecond,
+	}
+}
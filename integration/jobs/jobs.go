@@ -0,0 +1,109 @@
+// Package jobs logs the runs of cron and background jobs.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/common"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the job logs.
+const (
+	FieldJob       = "job"
+	FieldRunID     = "run_id"
+	FieldRun       = "run"
+	FieldOutcome   = "outcome"
+	FieldDuration  = "duration"
+	FieldHeartbeat = "heartbeat"
+)
+
+// Outcomes of a run.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomePanic   = "panic"
+)
+
+// Job is a named job whose runs are logged. Its Run method makes it a robfig/cron Job,
+// and Run can be passed where a func() is expected.
+type Job struct {
+	name string
+	fn   func(ctx context.Context) error
+	cfg  config
+	runs atomic.Uint64
+}
+
+// WrapJob wraps fn as the job name. Each run gets a child logger with the job name, a run
+// ID and the run number, available to fn through logger.Ctx(ctx). The start is logged at
+// Debug level and the end with the duration and outcome: at Info level on success, Error
+// when fn returns an error and Panic when it panics. Panics are recovered.
+func WrapJob(name string, fn func(ctx context.Context) error, opts ...JobOption) *Job {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Job{name: name, fn: fn, cfg: cfg}
+}
+
+// Run runs the job with a background context.
+func (j *Job) Run() {
+	j.RunContext(context.Background())
+}
+
+// RunContext runs the job with ctx and returns its error. A panic is returned as an error.
+func (j *Job) RunContext(ctx context.Context) (err error) {
+	base := &logger.Logger
+	if j.cfg.logger != nil {
+		base = j.cfg.logger
+	}
+	l := base.With().
+		Str(FieldJob, j.name).
+		Str(FieldRunID, xid.New().String()).
+		Uint64(FieldRun, j.runs.Add(1)).
+		Logger()
+	ctx = l.WithContext(ctx)
+
+	start := time.Now()
+	l.Debug().Msg("job started")
+	if j.cfg.heartbeat > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go heartbeat(l, start, j.cfg.heartbeat, stop)
+	}
+
+	defer func() {
+		rec := recover()
+		e := l.Info().Str(FieldOutcome, OutcomeSuccess)
+		switch {
+		case rec != nil:
+			err = fmt.Errorf("job %s panicked: %v", j.name, rec)
+			e = l.WithLevel(zerolog.PanicLevel).
+				Str(FieldOutcome, OutcomePanic).
+				EmbedObject(common.PanicValue(rec))
+		case err != nil:
+			e = l.Error().Str(FieldOutcome, OutcomeError).Err(err)
+		}
+		e.Dur(FieldDuration, time.Since(start)).Msg("job finished")
+	}()
+	return j.fn(ctx)
+}
+
+// heartbeat logs that the job is still running every interval until stop is closed
+func heartbeat(l zerolog.Logger, start time.Time, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.Info().Dur(FieldHeartbeat, time.Since(start)).Msg("job running")
+		}
+	}
+}
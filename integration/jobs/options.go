@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type JobOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger    *zerolog.Logger
+	heartbeat time.Duration
+}
+
+// WithLogger sets the logger the run loggers derive from. Default is the global
+// logger.Logger at the time of the run.
+func WithLogger(l zerolog.Logger) JobOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithHeartbeat logs a line with the heartbeat field, the time elapsed since the start,
+// every interval while the job runs, to tell slow jobs from stuck ones.
+func WithHeartbeat(interval time.Duration) JobOption {
+	return optionFunc(func(cfg *config) {
+		cfg.heartbeat = interval
+	})
+}
+
+func newDefaultConfig() config {
+	return config{}
+}
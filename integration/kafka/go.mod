@@ -0,0 +1,20 @@
+module github.com/XiBao/logger/integration/kafka
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/rs/zerolog v1.33.0
+	github.com/twmb/franz-go v1.17.1
+)
+
+require (
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/twmb/franz-go/pkg/kmsg v1.8.0 // indirect
+	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
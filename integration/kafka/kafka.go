@@ -0,0 +1,92 @@
+// Package kafka routes the logs of Kafka client libraries to zerolog.
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// SaramaLogger implements sarama.StdLogger, set it as sarama.Logger. Sarama has no levels,
+// every line is logged at the level of the logger.
+type SaramaLogger struct {
+	l     zerolog.Logger
+	level zerolog.Level
+}
+
+// NewSaramaLogger creates a SaramaLogger logging to l at level.
+func NewSaramaLogger(l zerolog.Logger, level zerolog.Level) *SaramaLogger {
+	return &SaramaLogger{l: l, level: level}
+}
+
+func (s *SaramaLogger) Print(v ...interface{}) {
+	s.l.WithLevel(s.level).Msg(strings.TrimSuffix(fmt.Sprint(v...), "\n"))
+}
+
+func (s *SaramaLogger) Printf(format string, v ...interface{}) {
+	s.l.WithLevel(s.level).Msg(strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+func (s *SaramaLogger) Println(v ...interface{}) {
+	s.l.WithLevel(s.level).Msg(strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+var _ = kgo.Logger(new(KgoLogger))
+
+// KgoLogger implements franz-go's kgo.Logger, set it with kgo.WithLogger. The key-value
+// pairs of the client become fields.
+type KgoLogger struct {
+	l zerolog.Logger
+}
+
+// NewKgoLogger creates a KgoLogger logging to l.
+func NewKgoLogger(l zerolog.Logger) *KgoLogger {
+	return &KgoLogger{l: l}
+}
+
+// Level returns the most verbose kgo level enabled on the logger, so that the client does
+// not build log lines that would be dropped.
+func (k *KgoLogger) Level() kgo.LogLevel {
+	level := k.l.GetLevel()
+	if global := zerolog.GlobalLevel(); global > level {
+		level = global
+	}
+	switch {
+	case level <= zerolog.DebugLevel:
+		return kgo.LogLevelDebug
+	case level == zerolog.InfoLevel:
+		return kgo.LogLevelInfo
+	case level == zerolog.WarnLevel:
+		return kgo.LogLevelWarn
+	case level == zerolog.ErrorLevel:
+		return kgo.LogLevelError
+	}
+	return kgo.LogLevelNone
+}
+
+func (k *KgoLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+	var e *zerolog.Event
+	switch level {
+	case kgo.LogLevelError:
+		e = k.l.Error()
+	case kgo.LogLevelWarn:
+		e = k.l.Warn()
+	case kgo.LogLevelInfo:
+		e = k.l.Info()
+	case kgo.LogLevelDebug:
+		e = k.l.Debug()
+	default:
+		return
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if err, ok := keyvals[i+1].(error); ok {
+			e = e.AnErr(key, err)
+			continue
+		}
+		e = e.Interface(key, keyvals[i+1])
+	}
+	e.Msg(msg)
+}
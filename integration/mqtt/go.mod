@@ -0,0 +1,19 @@
+module github.com/XiBao/logger/integration/mqtt
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.5.0
+	github.com/rs/zerolog v1.33.0
+)
+
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
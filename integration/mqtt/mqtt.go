@@ -0,0 +1,58 @@
+// Package mqtt routes the logs and connection events of the Eclipse Paho MQTT client to
+// zerolog.
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/rs/zerolog"
+)
+
+// FieldBroker is the broker URL of the connection logs.
+const FieldBroker = "broker"
+
+// SetLoggers routes the package-wide Paho loggers to l: ERROR and CRITICAL at Error level,
+// WARN at Warn level and DEBUG at Debug level.
+func SetLoggers(l zerolog.Logger) {
+	mqtt.ERROR = printer{l: l, level: zerolog.ErrorLevel}
+	mqtt.CRITICAL = printer{l: l, level: zerolog.ErrorLevel}
+	mqtt.WARN = printer{l: l, level: zerolog.WarnLevel}
+	mqtt.DEBUG = printer{l: l, level: zerolog.DebugLevel}
+}
+
+// SetHandlers sets the connection handlers of opts to log to l: connects at Info level,
+// lost connections at Warn level and reconnection attempts at Info level. They replace
+// the handlers already set on opts.
+func SetHandlers(opts *mqtt.ClientOptions, l zerolog.Logger) *mqtt.ClientOptions {
+	return opts.
+		SetOnConnectHandler(func(c mqtt.Client) {
+			e := l.Info()
+			r := c.OptionsReader()
+			if u := r.Servers(); len(u) > 0 {
+				e = e.Str(FieldBroker, u[0].Redacted())
+			}
+			e.Msg("mqtt connected")
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			l.Warn().Err(err).Msg("mqtt connection lost")
+		}).
+		SetReconnectingHandler(func(_ mqtt.Client, _ *mqtt.ClientOptions) {
+			l.Info().Msg("mqtt reconnecting")
+		})
+}
+
+// printer implements mqtt.Logger at a fixed level
+type printer struct {
+	l     zerolog.Logger
+	level zerolog.Level
+}
+
+func (p printer) Println(v ...interface{}) {
+	p.l.WithLevel(p.level).Msg(strings.TrimSpace(fmt.Sprintln(v...)))
+}
+
+func (p printer) Printf(format string, v ...interface{}) {
+	p.l.WithLevel(p.level).Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,20 @@
+module github.com/XiBao/logger/integration/nats
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/nats-io/nats.go v1.37.0
+	github.com/rs/zerolog v1.33.0
+)
+
+require (
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
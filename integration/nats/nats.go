@@ -0,0 +1,53 @@
+// Package nats logs the connection events of a NATS client.
+package nats
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the connection logs.
+const (
+	FieldURL        = "nats_url"
+	FieldSubject    = "subject"
+	FieldReconnects = "reconnects"
+	FieldServers    = "servers"
+)
+
+// Options returns the nats.Connect options logging the connection events to l: connects,
+// disconnects and reconnects at Info or Warn level, asynchronous errors such as slow
+// consumers at Error level. They replace the handlers set by earlier options.
+//
+//	nc, err := nats.Connect(url, natslog.Options(l)...)
+func Options(l zerolog.Logger) []nats.Option {
+	return []nats.Option{
+		nats.ConnectHandler(func(nc *nats.Conn) {
+			l.Info().Str(FieldURL, nc.ConnectedUrlRedacted()).Msg("nats connected")
+		}),
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			l.Warn().Err(err).Msg("nats disconnected")
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			l.Info().
+				Str(FieldURL, nc.ConnectedUrlRedacted()).
+				Uint64(FieldReconnects, nc.Stats().Reconnects).
+				Msg("nats reconnected")
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			l.Info().Err(nc.LastError()).Msg("nats connection closed")
+		}),
+		nats.DiscoveredServersHandler(func(nc *nats.Conn) {
+			l.Debug().Strs(FieldServers, nc.DiscoveredServers()).Msg("nats servers discovered")
+		}),
+		nats.LameDuckModeHandler(func(nc *nats.Conn) {
+			l.Warn().Str(FieldURL, nc.ConnectedUrlRedacted()).Msg("nats server entering lame duck mode")
+		}),
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			e := l.Error().Err(err)
+			if sub != nil {
+				e = e.Str(FieldSubject, sub.Subject)
+			}
+			e.Msg("nats error")
+		}),
+	}
+}
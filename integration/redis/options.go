@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"github.com/rs/zerolog"
+)
+
+type HookOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger    *zerolog.Logger
+	level     zerolog.Level
+	sanitizer Sanitizer
+}
+
+// WithLogger sets the logger used for command contexts without a logger. Default is the
+// global logger.Logger.
+func WithLogger(l zerolog.Logger) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithLevel sets the level of successful commands. Default is Debug.
+func WithLevel(level zerolog.Level) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = level
+	})
+}
+
+// WithSanitizer sets the Sanitizer of the logged arguments, nil logs them as is.
+// Default is HideValues.
+func WithSanitizer(sanitizer Sanitizer) HookOption {
+	return optionFunc(func(cfg *config) {
+		cfg.sanitizer = sanitizer
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level:     zerolog.DebugLevel,
+		sanitizer: HideValues,
+	}
+}
@@ -0,0 +1,174 @@
+// Package redis logs the commands sent by a go-redis client.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the command logs.
+const (
+	FieldCommand  = "redis_cmd"
+	FieldCommands = "redis_cmds"
+	FieldKeys     = "keys"
+	FieldDuration = "duration"
+)
+
+// Sanitizer returns the args of a command as they should be logged. args[0] is the
+// command name.
+type Sanitizer func(args []interface{}) []interface{}
+
+// HideValues is the default Sanitizer: it keeps the command name and the keys, and
+// replaces the other arguments with "?".
+func HideValues(args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	copy(out, args)
+	first, step, n := keyRange(args)
+	for i := 1; i < len(out); i++ {
+		if i < first || i >= first+n*step || (i-first)%step != 0 {
+			out[i] = "?"
+		}
+	}
+	return out
+}
+
+var _ = redis.Hook(new(Hook))
+
+// Hook logs commands and pipelines with the logger of the command context, or the
+// configured logger when the context has none: at Debug level, and at Error level when
+// they fail. redis.Nil is not a failure. Add it to a client with AddHook.
+type Hook struct {
+	cfg config
+}
+
+// NewHook creates a Hook.
+func NewHook(opts ...HookOption) *Hook {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Hook{cfg: cfg}
+}
+
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.logger(ctx).Error().Err(err).Str("addr", addr).Msg("redis dial failed")
+		}
+		return conn, err
+	}
+}
+
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		e := h.event(ctx, err)
+		if !e.Enabled() {
+			return err
+		}
+		_, _, keys := keyRange(cmd.Args())
+		e.Str(FieldCommand, h.format(cmd.Args())).
+			Int(FieldKeys, keys).
+			Dur(FieldDuration, time.Since(start)).
+			Msg("redis")
+		return err
+	}
+}
+
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		if err == nil {
+			for _, cmd := range cmds {
+				if cerr := cmd.Err(); cerr != nil && !errors.Is(cerr, redis.Nil) {
+					err = cerr
+					break
+				}
+			}
+		}
+		e := h.event(ctx, err)
+		if !e.Enabled() {
+			return err
+		}
+		arr := zerolog.Arr()
+		var keys int
+		for _, cmd := range cmds {
+			arr = arr.Str(h.format(cmd.Args()))
+			_, _, n := keyRange(cmd.Args())
+			keys += n
+		}
+		e.Array(FieldCommands, arr).
+			Int(FieldKeys, keys).
+			Dur(FieldDuration, time.Since(start)).
+			Msg("redis pipeline")
+		return err
+	}
+}
+
+func (h *Hook) event(ctx context.Context, err error) *zerolog.Event {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return h.logger(ctx).Error().Err(err)
+	}
+	return h.logger(ctx).WithLevel(h.cfg.level)
+}
+
+func (h *Hook) format(args []interface{}) string {
+	if h.cfg.sanitizer != nil {
+		args = h.cfg.sanitizer(args)
+	}
+	var b strings.Builder
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprint(&b, arg)
+	}
+	return b.String()
+}
+
+func (h *Hook) logger(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	if h.cfg.logger != nil {
+		return h.cfg.logger
+	}
+	return &logger.Logger
+}
+
+// keyRange returns the position of the first key in args, the distance between keys and
+// the number of keys
+func keyRange(args []interface{}) (first, step, n int) {
+	if len(args) < 2 {
+		return 1, 1, 0
+	}
+	name := strings.ToLower(fmt.Sprint(args[0]))
+	switch name {
+	case "ping", "echo", "info", "select", "auth", "hello", "client", "config", "dbsize",
+		"flushdb", "flushall", "multi", "exec", "discard", "unwatch", "scan", "script",
+		"publish", "subscribe", "psubscribe", "time", "command", "cluster":
+		return 1, 1, 0
+	case "mget", "del", "unlink", "exists", "touch", "watch", "sinter", "sunion", "sdiff", "pfcount":
+		return 1, 1, len(args) - 1
+	case "mset", "msetnx":
+		return 1, 2, (len(args) - 1) / 2
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		var keys int
+		if len(args) > 2 {
+			fmt.Sscan(fmt.Sprint(args[2]), &keys)
+		}
+		return 3, 1, max(min(keys, len(args)-3), 0)
+	}
+	return 1, 1, 1
+}
@@ -0,0 +1,57 @@
+package sqllog
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type ConnectorOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger     *zerolog.Logger
+	level      zerolog.Level
+	slow       time.Duration
+	redactArgs bool
+}
+
+// WithLogger sets the logger used for query contexts without a logger. Default is the
+// global logger.Logger.
+func WithLogger(l zerolog.Logger) ConnectorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithLevel sets the level of successful queries. Default is Debug.
+func WithLevel(level zerolog.Level) ConnectorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = level
+	})
+}
+
+// WithSlowThreshold logs queries taking at least threshold at Warn level.
+func WithSlowThreshold(threshold time.Duration) ConnectorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.slow = threshold
+	})
+}
+
+// WithRedactedArgs replaces the query arguments with Redacted, keeping their count.
+func WithRedactedArgs() ConnectorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.redactArgs = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level: zerolog.DebugLevel,
+	}
+}
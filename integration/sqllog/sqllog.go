@@ -0,0 +1,101 @@
+// Package sqllog logs the queries run through database/sql.
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the query logs.
+const (
+	FieldQuery        = "query"
+	FieldArgs         = "args"
+	FieldRowsAffected = "rows_affected"
+	FieldDuration     = "duration"
+)
+
+var errNamedArgs = errors.New("sqllog: driver does not support named arguments")
+
+// Redacted replaces the arguments when WithRedactedArgs is set.
+const Redacted = "[REDACTED]"
+
+// NewConnector wraps c so that every query, exec and transaction is logged with the logger
+// of the query context, or the configured logger when the context has none:
+//
+//	db := sql.OpenDB(sqllog.NewConnector(connector))
+//
+// Queries are logged at Debug level, at Warn when slower than the slow threshold and at
+// Error when they fail.
+func NewConnector(c driver.Connector, opts ...ConnectorOption) driver.Connector {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &connector{Connector: c, cfg: &cfg}
+}
+
+type connector struct {
+	driver.Connector
+	cfg *config
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, cfg: c.cfg}, nil
+}
+
+// log writes the line of a statement that started at start
+func (cfg *config) log(ctx context.Context, start time.Time, query string, args []driver.NamedValue, result driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		l = cfg.base()
+	}
+	elapsed := time.Since(start)
+	level := cfg.level
+	switch {
+	case err != nil && !errors.Is(err, driver.ErrBadConn):
+		level = zerolog.ErrorLevel
+	case cfg.slow > 0 && elapsed >= cfg.slow:
+		level = zerolog.WarnLevel
+	}
+	e := l.WithLevel(level)
+	if !e.Enabled() {
+		return
+	}
+	e = e.Err(err).Str(FieldQuery, query)
+	if len(args) > 0 {
+		arr := zerolog.Arr()
+		for _, arg := range args {
+			if cfg.redactArgs {
+				arr = arr.Str(Redacted)
+			} else {
+				arr = arr.Interface(arg.Value)
+			}
+		}
+		e = e.Array(FieldArgs, arr)
+	}
+	if result != nil {
+		if n, err := result.RowsAffected(); err == nil {
+			e = e.Int64(FieldRowsAffected, n)
+		}
+	}
+	e.Dur(FieldDuration, elapsed).Msg("sql")
+}
+
+func (cfg *config) base() *zerolog.Logger {
+	if cfg.logger != nil {
+		return cfg.logger
+	}
+	return &logger.Logger
+}
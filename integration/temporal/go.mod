@@ -0,0 +1,16 @@
+module github.com/XiBao/logger/integration/temporal
+
+go 1.22
+
+toolchain go1.22.6
+
+require (
+	github.com/rs/zerolog v1.33.0
+	go.temporal.io/sdk v1.29.1
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
@@ -0,0 +1,96 @@
+// Package temporal routes the logs of the Temporal SDK to zerolog.
+package temporal
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"go.temporal.io/sdk/log"
+)
+
+var (
+	_ = log.Logger(new(Logger))
+	_ = log.WithLogger(new(Logger))
+	_ = log.WithSkipCallers(new(Logger))
+)
+
+// Logger implements the Temporal log.Logger, set it as client.Options.Logger. The key-value
+// pairs of the SDK become fields under their own names, so the workflow and activity
+// metadata the SDK adds with With (WorkflowType, WorkflowID, RunID, ActivityType,
+// Attempt, ...) is preserved.
+type Logger struct {
+	l    zerolog.Logger
+	skip int
+}
+
+// New creates a Logger logging to l.
+func New(l zerolog.Logger) *Logger {
+	return &Logger{l: l}
+}
+
+func (t *Logger) Debug(msg string, keyvals ...interface{}) {
+	t.log(t.l.Debug(), msg, keyvals)
+}
+
+func (t *Logger) Info(msg string, keyvals ...interface{}) {
+	t.log(t.l.Info(), msg, keyvals)
+}
+
+func (t *Logger) Warn(msg string, keyvals ...interface{}) {
+	t.log(t.l.Warn(), msg, keyvals)
+}
+
+func (t *Logger) Error(msg string, keyvals ...interface{}) {
+	t.log(t.l.Error(), msg, keyvals)
+}
+
+// With returns a Logger adding keyvals to every line.
+func (t *Logger) With(keyvals ...interface{}) log.Logger {
+	return &Logger{l: fields(t.l.With(), keyvals).Logger(), skip: t.skip}
+}
+
+// WithCallerSkip returns a Logger adding the caller field, depth frames above the caller
+// of the log method, as the SDK does to point at workflow code.
+func (t *Logger) WithCallerSkip(depth int) log.Logger {
+	return &Logger{l: t.l, skip: t.skip + depth}
+}
+
+func (t *Logger) log(e *zerolog.Event, msg string, keyvals []interface{}) {
+	if !e.Enabled() {
+		return
+	}
+	if t.skip > 0 {
+		// the log method and this function
+		e = e.Caller(t.skip + 2)
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 == len(keyvals) {
+			e = e.Interface(key, nil)
+			break
+		}
+		switch v := keyvals[i+1].(type) {
+		case error:
+			e = e.AnErr(key, v)
+		default:
+			e = e.Interface(key, v)
+		}
+	}
+	e.Msg(msg)
+}
+
+func fields(c zerolog.Context, keyvals []interface{}) zerolog.Context {
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 == len(keyvals) {
+			return c.Interface(key, nil)
+		}
+		switch v := keyvals[i+1].(type) {
+		case error:
+			c = c.AnErr(key, v)
+		default:
+			c = c.Interface(key, v)
+		}
+	}
+	return c
+}
@@ -0,0 +1,74 @@
+// Package sentryutil holds the conversions shared by the Sentry hook and writer. It is a
+// module of its own, so the root module does not depend on the Sentry SDK.
+package sentryutil
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// ErrWithStackTrace is the stack field written by CaptureErr of the Sentry hook and by
+// common.PanicValue.
+type ErrWithStackTrace struct {
+	Stacktrace *sentry.Stacktrace `json:"stacktrace"`
+	Err        string             `json:"error"`
+}
+
+// Stacktrace returns the stack of the caller of the logger, without the frames of the
+// logger package and of zerolog.
+func Stacktrace() *sentry.Stacktrace {
+	const (
+		currentModule = "github.com/XiBao/logger"
+		zerologModule = "github.com/rs/zerolog"
+	)
+
+	st := sentry.NewStacktrace()
+
+	threshold := len(st.Frames) - 1
+	// drop current module frames
+	for ; threshold > 0 && st.Frames[threshold].Module == currentModule; threshold-- {
+	}
+
+outer:
+	// try to drop zerolog module frames after logger call point
+	for i := threshold; i > 0; i-- {
+		if st.Frames[i].Module == zerologModule {
+			for j := i - 1; j >= 0; j-- {
+				if st.Frames[j].Module != zerologModule {
+					threshold = j
+					break outer
+				}
+			}
+
+			break
+		}
+	}
+
+	st.Frames = st.Frames[:threshold+1]
+
+	return st
+}
+
+// CallerStacktrace synthesizes a single frame stacktrace from a zerolog caller field
+// formatted as file:line.
+func CallerStacktrace(caller string) *sentry.Stacktrace {
+	idx := strings.LastIndexByte(caller, ':')
+	if idx <= 0 {
+		return nil
+	}
+	line, err := strconv.Atoi(caller[idx+1:])
+	if err != nil {
+		return nil
+	}
+	file := caller[:idx]
+	return &sentry.Stacktrace{
+		Frames: []sentry.Frame{{
+			Filename: file,
+			AbsPath:  file,
+			Lineno:   line,
+			InApp:    true,
+		}},
+	}
+}
@@ -0,0 +1,70 @@
+package sentryutil
+
+import (
+	"github.com/XiBao/logger/common"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// LevelsMapping maps zerolog levels to the Sentry levels events are reported as. Sentry has
+// no trace and panic levels: trace events are reported as debug and panic ones as fatal.
+var LevelsMapping = map[zerolog.Level]sentry.Level{
+	zerolog.TraceLevel: sentry.LevelDebug,
+	zerolog.DebugLevel: sentry.LevelDebug,
+	zerolog.InfoLevel:  sentry.LevelInfo,
+	zerolog.WarnLevel:  sentry.LevelWarning,
+	zerolog.ErrorLevel: sentry.LevelError,
+	zerolog.FatalLevel: sentry.LevelFatal,
+	zerolog.PanicLevel: sentry.LevelFatal,
+}
+
+// SetTags copies the values of fields of the encoded log line to the event tags.
+func SetTags(event *sentry.Event, data []byte, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	for i, res := range gjson.GetManyBytes(data, escapePaths(fields)...) {
+		if !res.Exists() {
+			continue
+		}
+		if event.Tags == nil {
+			event.Tags = make(map[string]string, len(fields))
+		}
+		event.Tags[fields[i]] = res.String()
+	}
+}
+
+// SetUser fills the event user from the fields of the encoded log line.
+func SetUser(event *sentry.Event, data []byte, fields common.UserFields) {
+	if fields == (common.UserFields{}) {
+		return
+	}
+	paths := []string{fields.ID, fields.Email, fields.Username, fields.Name, fields.IPAddress}
+	res := gjson.GetManyBytes(data, escapePaths(paths)...)
+	value := func(i int) string {
+		if paths[i] == "" {
+			return ""
+		}
+		return res[i].String()
+	}
+	user := sentry.User{
+		ID:        value(0),
+		Email:     value(1),
+		Username:  value(2),
+		Name:      value(3),
+		IPAddress: value(4),
+	}
+	if user.IsEmpty() {
+		return
+	}
+	event.User = user
+}
+
+func escapePaths(fields []string) []string {
+	paths := make([]string, len(fields))
+	for i, f := range fields {
+		paths[i] = common.EscapePath(f)
+	}
+	return paths
+}
@@ -0,0 +1,75 @@
+// Package sinkerr counts the delivery errors of the network writers and hooks and passes
+// them to a process-wide handler, see logger.SetSinkErrorHandler.
+package sinkerr
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	handler atomic.Value // func(sink string, err error)
+	counts  sync.Map     // sink name -> *atomic.Uint64
+)
+
+func init() {
+	handler.Store(defaultHandler)
+}
+
+// SetHandler replaces the handler invoked with the errors of writers without a handler of
+// their own. A nil fn discards the errors; they are counted regardless.
+func SetHandler(fn func(sink string, err error)) {
+	if fn == nil {
+		fn = func(string, error) {}
+	}
+	handler.Store(fn)
+}
+
+// Handle passes err to the process-wide handler. Writers use it as their default error handler.
+func Handle(sink string, err error) {
+	handler.Load().(func(string, error))(sink, err)
+}
+
+// Counting returns an error handler counting the errors of sink before passing them to fn.
+// fn may be nil.
+func Counting(sink string, fn func(error)) func(error) {
+	return func(err error) {
+		Inc(sink)
+		if fn != nil {
+			fn(err)
+		}
+	}
+}
+
+// Inc counts one error of sink.
+func Inc(sink string) {
+	c, ok := counts.Load(sink)
+	if !ok {
+		c, _ = counts.LoadOrStore(sink, new(atomic.Uint64))
+	}
+	c.(*atomic.Uint64).Add(1)
+}
+
+// Count returns the number of errors counted for sink.
+func Count(sink string) uint64 {
+	if c, ok := counts.Load(sink); ok {
+		return c.(*atomic.Uint64).Load()
+	}
+	return 0
+}
+
+// Counts returns the number of errors counted per sink.
+func Counts() map[string]uint64 {
+	out := make(map[string]uint64)
+	counts.Range(func(k, v interface{}) bool {
+		out[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return out
+}
+
+func defaultHandler(sink string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", sink, err)
+}
@@ -2,30 +2,16 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
-	"path"
-	"strconv"
-	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-func init() {
-	zerolog.CallerMarshalFunc = func(pc uintptr, file string, line int) string {
-		dir, fileName := path.Split(file)
-		_, lastPath := path.Split(strings.TrimSuffix(dir, "/"))
-		filePath := fileName
-		if lastPath != "" {
-			filePath = path.Join(lastPath, fileName)
-		}
-		return filePath + ":" + strconv.Itoa(line)
-	}
-}
-
 var (
 	Logger     = log.Logger.With().Logger()
-	LoggerHook = log.Logger.With().Caller().Logger()
+	LoggerHook = log.Logger.Hook(sourceHook{format: DefaultCallerFormat})
 )
 
 func Array(v ...interface{}) *zerolog.Array {
@@ -41,7 +27,7 @@ func Array(v ...interface{}) *zerolog.Array {
 
 func SetLogger(logger zerolog.Logger) {
 	Logger = logger.With().Logger()
-	LoggerHook = logger.With().CallerWithSkipFrameCount(2).Logger()
+	LoggerHook = logger.Hook(sourceHook{format: DefaultCallerFormat})
 }
 
 // Output duplicates the global logger and sets w as its output.
@@ -146,13 +132,17 @@ func Log() *zerolog.Event {
 // Print sends a log event using debug level and no extra field.
 // Arguments are handled in the manner of fmt.Print.
 func Print(v ...interface{}) {
-	LoggerHook.Print(v...)
+	if e := LoggerHook.Debug(); e.Enabled() {
+		e.CallerSkipFrame(1).Msg(fmt.Sprint(v...))
+	}
 }
 
 // Printf sends a log event using debug level and no extra field.
 // Arguments are handled in the manner of fmt.Printf.
 func Printf(format string, v ...interface{}) {
-	LoggerHook.Printf(format, v...)
+	if e := LoggerHook.Debug(); e.Enabled() {
+		e.CallerSkipFrame(1).Msg(fmt.Sprintf(format, v...))
+	}
 }
 
 func WithContext(ctx context.Context) context.Context {
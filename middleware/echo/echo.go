@@ -0,0 +1,112 @@
+// Package echo logs the requests served by an Echo server.
+package echo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/common"
+	httplog "github.com/XiBao/logger/middleware/http"
+	"github.com/XiBao/logger/requestid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// FieldRoute is the echo route pattern of the request, e.g. /users/:id.
+const FieldRoute = "route"
+
+// Logger gives every request a logger with its request ID, remote IP, method and path,
+// available through logger.Ctx(c.Request().Context()), and logs the completion of the
+// request: at Error level for 5xx, Warn for 4xx and Info otherwise. Errors returned by the
+// handler go through the echo error handler first, so the status is the one sent.
+func Logger(opts ...MiddlewareOption) echo.MiddlewareFunc {
+	cfg := newConfig(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			r := c.Request()
+
+			id := requestid.Resolve(r.Header.Get(cfg.requestIDHeader))
+			c.Response().Header().Set(cfg.requestIDHeader, id)
+
+			l := cfg.base().With().
+				Str(httplog.FieldRequestID, id).
+				Str(httplog.FieldRemoteIP, c.RealIP()).
+				Str(httplog.FieldMethod, r.Method).
+				Str(httplog.FieldPath, r.URL.Path).
+				Logger()
+			if logger.DebugRequested(r.Context(), cfg.debugSecret, r.Header.Get(logger.DebugHeader)) {
+				l = logger.DebugLogger(l)
+			}
+			c.SetRequest(r.WithContext(l.WithContext(requestid.NewContext(r.Context(), id))))
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			if cfg.skip[r.URL.Path] {
+				return err
+			}
+			status := c.Response().Status
+			level := zerolog.InfoLevel
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = zerolog.ErrorLevel
+			case status >= http.StatusBadRequest:
+				level = zerolog.WarnLevel
+			}
+			l.WithLevel(level).
+				Err(err).
+				Str(httplog.FieldURL, r.URL.RequestURI()).
+				Int(httplog.FieldStatus, status).
+				Int64(httplog.FieldBytes, c.Response().Size).
+				Dur(httplog.FieldLatency, time.Since(start)).
+				Str(httplog.FieldUserAgent, r.UserAgent()).
+				Str(FieldRoute, c.Path()).
+				Msg("request completed")
+			return err
+		}
+	}
+}
+
+// Recovery recovers panics of the handlers and logs them at Panic level with the stack,
+// so hooks such as the Sentry hook report them, then answers with a 500.
+func Recovery(opts ...MiddlewareOption) echo.MiddlewareFunc {
+	cfg := newConfig(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				perr, ok := rec.(error)
+				if !ok {
+					perr = fmt.Errorf("%v", rec)
+				}
+				l := zerolog.Ctx(c.Request().Context())
+				if l.GetLevel() == zerolog.Disabled {
+					l = cfg.base()
+				}
+				l.WithLevel(zerolog.PanicLevel).
+					EmbedObject(common.PanicValue(rec)).
+					Msg("panic recovered")
+				err = echo.NewHTTPError(http.StatusInternalServerError).SetInternal(perr)
+			}()
+			return next(c)
+		}
+	}
+}
+
+func (cfg *config) base() *zerolog.Logger {
+	if cfg.logger != nil {
+		return cfg.logger
+	}
+	return &logger.Logger
+}
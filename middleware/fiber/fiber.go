@@ -0,0 +1,105 @@
+// Package fiber logs the requests served by a Fiber app.
+package fiber
+
+import (
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/common"
+	httplog "github.com/XiBao/logger/middleware/http"
+	"github.com/XiBao/logger/requestid"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/rs/zerolog"
+)
+
+// FieldRoute is the fiber route pattern of the request, e.g. /users/:id.
+const FieldRoute = "route"
+
+// Logger gives every request a logger with its request ID, remote IP, method and path,
+// available through logger.Ctx(c.UserContext()), and logs the completion of the request:
+// at Error level for 5xx, Warn for 4xx and Info otherwise. Errors returned by the handlers
+// go through the app error handler first, so the status is the one sent.
+func Logger(opts ...MiddlewareOption) fiber.Handler {
+	cfg := newConfig(opts)
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		// the strings of fiber point into the request buffer, which is reused once the
+		// handler returns, so the ID kept in the context and the path checked after Next
+		// are copied
+		id := utils.CopyString(requestid.Resolve(c.Get(cfg.requestIDHeader)))
+		c.Set(cfg.requestIDHeader, id)
+
+		path := utils.CopyString(c.Path())
+		l := cfg.base().With().
+			Str(httplog.FieldRequestID, id).
+			Str(httplog.FieldRemoteIP, c.IP()).
+			Str(httplog.FieldMethod, c.Method()).
+			Str(httplog.FieldPath, path).
+			Logger()
+		if logger.DebugRequested(c.UserContext(), cfg.debugSecret, c.Get(logger.DebugHeader)) {
+			l = logger.DebugLogger(l)
+		}
+		c.SetUserContext(l.WithContext(requestid.NewContext(c.UserContext(), id)))
+
+		err := c.Next()
+		if err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		if cfg.skip[path] {
+			return nil
+		}
+		status := c.Response().StatusCode()
+		level := zerolog.InfoLevel
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = zerolog.ErrorLevel
+		case status >= fiber.StatusBadRequest:
+			level = zerolog.WarnLevel
+		}
+		l.WithLevel(level).
+			Err(err).
+			Str(httplog.FieldURL, c.OriginalURL()).
+			Int(httplog.FieldStatus, status).
+			Int(httplog.FieldBytes, len(c.Response().Body())).
+			Dur(httplog.FieldLatency, time.Since(start)).
+			Str(httplog.FieldUserAgent, c.Get(fiber.HeaderUserAgent)).
+			Str(FieldRoute, c.Route().Path).
+			Msg("request completed")
+		return nil
+	}
+}
+
+// Recovery recovers panics of the handlers and logs them at Panic level with the stack,
+// so hooks such as the Sentry hook report them, then answers with a 500.
+func Recovery(opts ...MiddlewareOption) fiber.Handler {
+	cfg := newConfig(opts)
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			l := zerolog.Ctx(c.UserContext())
+			if l.GetLevel() == zerolog.Disabled {
+				l = cfg.base()
+			}
+			l.WithLevel(zerolog.PanicLevel).
+				EmbedObject(common.PanicValue(rec)).
+				Msg("panic recovered")
+			err = fiber.ErrInternalServerError
+		}()
+		return c.Next()
+	}
+}
+
+func (cfg *config) base() *zerolog.Logger {
+	if cfg.logger != nil {
+		return cfg.logger
+	}
+	return &logger.Logger
+}
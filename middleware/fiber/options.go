@@ -0,0 +1,69 @@
+package fiber
+
+import (
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+)
+
+type MiddlewareOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger          *zerolog.Logger
+	skip            map[string]bool
+	requestIDHeader string
+	debugSecret     []byte
+}
+
+// WithLogger sets the logger the request loggers derive from. Default is the global
+// logger.Logger at the time of the request.
+func WithLogger(l zerolog.Logger) MiddlewareOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithSkipPaths does not log the completion of requests to paths, such as health checks.
+func WithSkipPaths(paths ...string) MiddlewareOption {
+	return optionFunc(func(cfg *config) {
+		for _, p := range paths {
+			cfg.skip[p] = true
+		}
+	})
+}
+
+// WithRequestIDHeader sets the header carrying the request ID. Default is X-Request-ID.
+func WithRequestIDHeader(name string) MiddlewareOption {
+	return optionFunc(func(cfg *config) {
+		cfg.requestIDHeader = name
+	})
+}
+
+// WithDebugToken lowers the request logger to debug for requests carrying a valid debug
+// token for secret in the X-Debug-Token header, see logger.NewDebugToken. Requests whose
+// context was returned by logger.WithDebug get a debug logger regardless.
+func WithDebugToken(secret []byte) MiddlewareOption {
+	return optionFunc(func(cfg *config) {
+		cfg.debugSecret = secret
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		skip:            make(map[string]bool),
+		requestIDHeader: requestid.Header,
+	}
+}
+
+func newConfig(opts []MiddlewareOption) *config {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &cfg
+}
@@ -0,0 +1,133 @@
+// Package gin replaces gin.Logger and gin.Recovery with structured logging.
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/common"
+	httplog "github.com/XiBao/logger/middleware/http"
+	"github.com/XiBao/logger/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// FieldRoute is the gin route pattern of the request, e.g. /users/:id.
+const FieldRoute = "route"
+
+// Logger gives every request a logger with its request ID, remote IP, method and path,
+// available through logger.Ctx(c.Request.Context()), and logs the completion of the
+// request: at Error level for 5xx, Warn for 4xx and Info otherwise. Errors attached to the
+// gin context with c.Error are added to the line.
+func Logger(opts ...MiddlewareOption) gin.HandlerFunc {
+	cfg := newConfig(opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		r := c.Request
+
+		id := requestid.Resolve(r.Header.Get(cfg.requestIDHeader))
+		c.Header(cfg.requestIDHeader, id)
+
+		l := cfg.base().With().
+			Str(httplog.FieldRequestID, id).
+			Str(httplog.FieldRemoteIP, c.ClientIP()).
+			Str(httplog.FieldMethod, r.Method).
+			Str(httplog.FieldPath, r.URL.Path).
+			Logger()
+		if logger.DebugRequested(r.Context(), cfg.debugSecret, r.Header.Get(logger.DebugHeader)) {
+			l = logger.DebugLogger(l)
+		}
+		c.Request = r.WithContext(l.WithContext(requestid.NewContext(r.Context(), id)))
+
+		c.Next()
+
+		if cfg.skip[r.URL.Path] {
+			return
+		}
+		status := c.Writer.Status()
+		level := zerolog.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zerolog.ErrorLevel
+		case status >= http.StatusBadRequest:
+			level = zerolog.WarnLevel
+		}
+		e := l.WithLevel(level).
+			Str(httplog.FieldURL, r.URL.RequestURI()).
+			Int(httplog.FieldStatus, status).
+			Int(httplog.FieldBytes, max(c.Writer.Size(), 0)).
+			Dur(httplog.FieldLatency, time.Since(start)).
+			Str(httplog.FieldUserAgent, r.UserAgent())
+		if route := c.FullPath(); route != "" {
+			e = e.Str(FieldRoute, route)
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			e = e.Str(zerolog.ErrorFieldName, errs.String())
+		}
+		e.Msg("request completed")
+	}
+}
+
+// Recovery recovers panics of the handlers and logs them at Panic level with the stack,
+// so hooks such as the Sentry hook report them, then aborts the request with a 500.
+// Broken connections are logged at Error level and not answered.
+func Recovery(opts ...MiddlewareOption) gin.HandlerFunc {
+	cfg := newConfig(opts)
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			l := zerolog.Ctx(c.Request.Context())
+			if l.GetLevel() == zerolog.Disabled {
+				l = cfg.base()
+			}
+			if brokenPipe(err) {
+				l.Error().Err(err).Msg("connection broken")
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			l.WithLevel(zerolog.PanicLevel).
+				EmbedObject(common.PanicValue(rec)).
+				Msg("panic recovered")
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+// brokenPipe reports whether err is the client closing the connection
+func brokenPipe(err error) bool {
+	var ne *net.OpError
+	if !errors.As(err, &ne) {
+		return false
+	}
+	var se *os.SyscallError
+	if errors.As(ne, &se) {
+		if errors.Is(se.Err, syscall.EPIPE) || errors.Is(se.Err, syscall.ECONNRESET) {
+			return true
+		}
+	}
+	msg := strings.ToLower(ne.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
+
+func (cfg *config) base() *zerolog.Logger {
+	if cfg.logger != nil {
+		return cfg.logger
+	}
+	return &logger.Logger
+}
@@ -0,0 +1,247 @@
+// Package grpc logs the RPCs of gRPC servers and clients.
+package grpc
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Field names of the RPC logs.
+const (
+	FieldRequestID    = requestid.Field
+	FieldService      = "grpc_service"
+	FieldMethod       = "grpc_method"
+	FieldCode         = "grpc_code"
+	FieldPeer         = "peer"
+	FieldLatency      = "latency"
+	FieldRequestSize  = "request_size"
+	FieldResponseSize = "response_size"
+)
+
+// DefaultCodeLevels maps gRPC codes to the level of the finish line. Codes missing from
+// the map are logged at Error level.
+var DefaultCodeLevels = map[codes.Code]zerolog.Level{
+	codes.OK:                 zerolog.InfoLevel,
+	codes.Canceled:           zerolog.InfoLevel,
+	codes.InvalidArgument:    zerolog.InfoLevel,
+	codes.NotFound:           zerolog.InfoLevel,
+	codes.AlreadyExists:      zerolog.InfoLevel,
+	codes.Unauthenticated:    zerolog.InfoLevel,
+	codes.DeadlineExceeded:   zerolog.WarnLevel,
+	codes.PermissionDenied:   zerolog.WarnLevel,
+	codes.ResourceExhausted:  zerolog.WarnLevel,
+	codes.FailedPrecondition: zerolog.WarnLevel,
+	codes.Aborted:            zerolog.WarnLevel,
+	codes.OutOfRange:         zerolog.WarnLevel,
+}
+
+// UnaryServerInterceptor logs unary RPCs and gives handlers a logger with the RPC fields
+// through logger.Ctx(ctx).
+func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		r := cfg.start(ctx, info.FullMethod, nil)
+		resp, err := handler(r.ctx, req)
+		r.finish(err, size(req), size(resp))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs streaming RPCs and gives handlers a logger with the RPC
+// fields through logger.Ctx(stream.Context()). Sizes are the totals of the stream.
+func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		r := cfg.start(ss.Context(), info.FullMethod, nil)
+		stream := &serverStream{ServerStream: ss, ctx: r.ctx}
+		err := handler(srv, stream)
+		r.finish(err, stream.recv, stream.sent)
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs outgoing unary RPCs with the logger of the call context.
+func UnaryClientInterceptor(opts ...InterceptorOption) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		r := cfg.start(ctx, method, cc)
+		err := invoker(r.ctx, method, req, reply, cc, callOpts...)
+		r.finish(err, size(req), size(reply))
+		return err
+	}
+}
+
+// StreamClientInterceptor logs outgoing streaming RPCs once the stream ends.
+func StreamClientInterceptor(opts ...InterceptorOption) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		r := cfg.start(ctx, method, cc)
+		cs, err := streamer(r.ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			r.finish(err, 0, 0)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, rpc: r}, nil
+	}
+}
+
+// rpc is an RPC being logged
+type rpc struct {
+	cfg   *config
+	ctx   context.Context
+	l     zerolog.Logger
+	peer  string
+	start time.Time
+}
+
+// start logs the start of an RPC, served when cc is nil and called on cc otherwise
+func (cfg *config) start(ctx context.Context, fullMethod string, cc *grpc.ClientConn) *rpc {
+	server := cc == nil
+	base := &logger.Logger
+	if cfg.logger != nil {
+		base = cfg.logger
+	}
+	if l := zerolog.Ctx(ctx); !server && l.GetLevel() != zerolog.Disabled {
+		base = l
+	}
+	service, method := path.Split(fullMethod)
+	c := base.With().
+		Str(FieldService, path.Clean(service)[1:]).
+		Str(FieldMethod, method)
+	r := &rpc{cfg: cfg, ctx: ctx, start: time.Now()}
+	if server {
+		var inbound string
+		if ids := metadata.ValueFromIncomingContext(ctx, cfg.requestIDKey); len(ids) > 0 {
+			inbound = ids[0]
+		}
+		id := requestid.Resolve(inbound)
+		grpc.SetHeader(ctx, metadata.Pairs(cfg.requestIDKey, id))
+		c = c.Str(FieldRequestID, id)
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			r.peer = p.Addr.String()
+		}
+		r.l = c.Logger()
+		var token string
+		if tokens := metadata.ValueFromIncomingContext(ctx, strings.ToLower(logger.DebugHeader)); len(tokens) > 0 {
+			token = tokens[0]
+		}
+		if logger.DebugRequested(ctx, cfg.debugSecret, token) {
+			r.l = logger.DebugLogger(r.l)
+		}
+		r.ctx = r.l.WithContext(requestid.NewContext(ctx, id))
+	} else {
+		// forward the request ID of the context
+		if id, ok := requestid.FromContext(ctx); ok {
+			if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get(cfg.requestIDKey)) == 0 {
+				r.ctx = metadata.AppendToOutgoingContext(ctx, cfg.requestIDKey, id)
+			}
+		}
+		r.peer = cc.Target()
+		r.l = c.Logger()
+	}
+	r.l.Debug().Str(FieldPeer, r.peer).Msg("rpc started")
+	return r
+}
+
+func (r *rpc) finish(err error, reqSize, respSize int) {
+	code := status.Code(err)
+	level, ok := r.cfg.levels[code]
+	if !ok {
+		level = zerolog.ErrorLevel
+	}
+	r.l.WithLevel(level).
+		Err(err).
+		Str(FieldCode, code.String()).
+		Str(FieldPeer, r.peer).
+		Dur(FieldLatency, time.Since(r.start)).
+		Int(FieldRequestSize, reqSize).
+		Int(FieldResponseSize, respSize).
+		Msg("rpc finished")
+}
+
+func size(msg any) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv int
+	sent int
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent += size(m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.recv += size(m)
+	}
+	return err
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	rpc  *rpc
+	sent int
+	recv int
+	done bool
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent += size(m)
+	} else {
+		s.end(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.recv += size(m)
+		return nil
+	}
+	s.end(err)
+	return err
+}
+
+// end logs the stream once, io.EOF being a successful end
+func (s *clientStream) end(err error) {
+	if s.done {
+		return
+	}
+	s.done = true
+	if err == io.EOF {
+		err = nil
+	}
+	s.rpc.finish(err, s.sent, s.recv)
+}
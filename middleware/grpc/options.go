@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+)
+
+type InterceptorOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger       *zerolog.Logger
+	levels       map[codes.Code]zerolog.Level
+	requestIDKey string
+	debugSecret  []byte
+}
+
+// WithLogger sets the logger of the RPC logs. Default is the global logger.Logger. Client
+// interceptors use the logger of the call context instead when it has one.
+func WithLogger(l zerolog.Logger) InterceptorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithCodeLevels overrides the level of the finish line for the given codes, on top of
+// DefaultCodeLevels.
+func WithCodeLevels(levels map[codes.Code]zerolog.Level) InterceptorOption {
+	return optionFunc(func(cfg *config) {
+		for code, level := range levels {
+			cfg.levels[code] = level
+		}
+	})
+}
+
+// WithRequestIDKey sets the metadata key holding the request ID. Servers reuse the inbound
+// ID, or generate one, and send it back in the header; clients forward the ID of the call
+// context. Default is x-request-id.
+func WithRequestIDKey(key string) InterceptorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.requestIDKey = key
+	})
+}
+
+// WithDebugToken lowers the logger of served RPCs to debug when they carry a valid debug
+// token for secret in the x-debug-token metadata, see logger.NewDebugToken. RPCs whose
+// context was returned by logger.WithDebug get a debug logger regardless.
+func WithDebugToken(secret []byte) InterceptorOption {
+	return optionFunc(func(cfg *config) {
+		cfg.debugSecret = secret
+	})
+}
+
+func newDefaultConfig() config {
+	levels := make(map[codes.Code]zerolog.Level, len(DefaultCodeLevels))
+	for code, level := range DefaultCodeLevels {
+		levels[code] = level
+	}
+	return config{
+		levels:       levels,
+		requestIDKey: "x-request-id",
+	}
+}
+
+func newConfig(opts []InterceptorOption) *config {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &cfg
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// accessLog writes Apache/NCSA combined format lines
+type accessLog struct {
+	w   io.Writer
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (a *accessLog) write(r *http.Request, start time.Time, status int, size int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.buf = appendCombined(a.buf[:0], r, start, status, size)
+	a.w.Write(a.buf)
+}
+
+// appendCombined appends the combined format line of a request:
+//
+//	host ident user [time] "request" status bytes "referer" "user-agent"
+func appendCombined(dst []byte, r *http.Request, start time.Time, status int, size int64) []byte {
+	dst = append(dst, remoteIP(r)...)
+	dst = append(dst, " - "...)
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	} else if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	dst = appendEscaped(dst, user)
+	dst = append(dst, " ["...)
+	dst = start.AppendFormat(dst, "02/Jan/2006:15:04:05 -0700")
+	dst = append(dst, `] "`...)
+	dst = appendEscaped(dst, r.Method)
+	dst = append(dst, ' ')
+	dst = appendEscaped(dst, r.URL.RequestURI())
+	dst = append(dst, ' ')
+	dst = appendEscaped(dst, r.Proto)
+	dst = append(dst, `" `...)
+	dst = strconv.AppendInt(dst, int64(status), 10)
+	dst = append(dst, ' ')
+	if size > 0 {
+		dst = strconv.AppendInt(dst, size, 10)
+	} else {
+		dst = append(dst, '-')
+	}
+	dst = append(dst, ` "`...)
+	dst = appendEscaped(dst, orDash(r.Referer()))
+	dst = append(dst, `" "`...)
+	dst = appendEscaped(dst, orDash(r.UserAgent()))
+	return append(dst, '"', '\n')
+}
+
+// appendEscaped writes s with quotes, backslashes and control characters escaped, as
+// Apache does
+func appendEscaped(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			dst = append(dst, '\\', 'x', hex[c>>4], hex[c&0xF])
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
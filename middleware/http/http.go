@@ -0,0 +1,160 @@
+// Package http logs the requests served by a net/http handler.
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the request logs.
+const (
+	FieldRequestID = requestid.Field
+	FieldRemoteIP  = "remote_ip"
+	FieldMethod    = "http_method"
+	FieldPath      = "path"
+	FieldURL       = "url"
+	FieldStatus    = "status"
+	FieldBytes     = "bytes"
+	FieldLatency   = "latency"
+	FieldUserAgent = "user_agent"
+)
+
+// Handler wraps next with request logging. Every request gets a logger with its request
+// ID, remote IP, method and path, available to next through logger.Ctx(r.Context()).
+// When next returns, a completion line is logged with the status, response size and
+// latency: at Error level for 5xx, Warn for 4xx and the route level otherwise. With
+// WithAccessLog, a combined format line is also written for legacy tooling.
+func Handler(next http.Handler, opts ...HandlerOption) http.Handler {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	h := &handler{next: next, cfg: cfg}
+	if cfg.accessLog != nil {
+		h.access = &accessLog{w: cfg.accessLog}
+	}
+	return h
+}
+
+type handler struct {
+	next   http.Handler
+	cfg    config
+	access *accessLog
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	id := requestid.Resolve(r.Header.Get(h.cfg.requestIDHeader))
+	w.Header().Set(h.cfg.requestIDHeader, id)
+
+	base := logger.Logger
+	if h.cfg.logger != nil {
+		base = *h.cfg.logger
+	}
+	l := base.With().
+		Str(FieldRequestID, id).
+		Str(FieldRemoteIP, remoteIP(r)).
+		Str(FieldMethod, r.Method).
+		Str(FieldPath, r.URL.Path).
+		Logger()
+	if logger.DebugRequested(r.Context(), h.cfg.debugSecret, r.Header.Get(logger.DebugHeader)) {
+		l = logger.DebugLogger(l)
+	}
+	r = r.WithContext(l.WithContext(requestid.NewContext(r.Context(), id)))
+
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(rw, r)
+
+	if h.cfg.skip[r.URL.Path] {
+		return
+	}
+	if h.access != nil {
+		h.access.write(r, start, rw.status, rw.bytes)
+	}
+	var level zerolog.Level
+	switch {
+	case rw.status >= http.StatusInternalServerError:
+		level = zerolog.ErrorLevel
+	case rw.status >= http.StatusBadRequest:
+		level = zerolog.WarnLevel
+	default:
+		level = h.cfg.routeLevel(r.URL.Path)
+	}
+	l.WithLevel(level).
+		Str(FieldURL, r.URL.RequestURI()).
+		Int(FieldStatus, rw.status).
+		Int64(FieldBytes, rw.bytes).
+		Dur(FieldLatency, time.Since(start)).
+		Str(FieldUserAgent, r.UserAgent()).
+		Msg("request completed")
+}
+
+// routeLevel returns the level of the longest route prefix matching path
+func (cfg *config) routeLevel(path string) zerolog.Level {
+	level, matched := cfg.level, -1
+	for prefix, lvl := range cfg.routes {
+		if len(prefix) > matched && strings.HasPrefix(path, prefix) {
+			level, matched = lvl, len(prefix)
+		}
+	}
+	return level
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseWriter records the status and size of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("http: response does not implement http.Hijacker")
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
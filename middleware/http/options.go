@@ -0,0 +1,94 @@
+package http
+
+import (
+	"io"
+
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+)
+
+type HandlerOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	logger          *zerolog.Logger
+	level           zerolog.Level
+	routes          map[string]zerolog.Level
+	skip            map[string]bool
+	requestIDHeader string
+	accessLog       io.Writer
+	debugSecret     []byte
+}
+
+// WithLogger sets the logger the request loggers derive from. Default is the global
+// logger.Logger at the time of the request.
+func WithLogger(l zerolog.Logger) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.logger = &l
+	})
+}
+
+// WithLevel sets the level of successful requests. Default is Info.
+func WithLevel(level zerolog.Level) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = level
+	})
+}
+
+// WithRouteLevel sets the level of successful requests whose path starts with prefix,
+// e.g. Debug for a chatty polling endpoint. The longest matching prefix wins.
+func WithRouteLevel(prefix string, level zerolog.Level) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.routes[prefix] = level
+	})
+}
+
+// WithSkipPaths does not log the completion of requests to paths, such as health checks.
+// Their handlers still get a request logger.
+func WithSkipPaths(paths ...string) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		for _, p := range paths {
+			cfg.skip[p] = true
+		}
+	})
+}
+
+// WithRequestIDHeader sets the header carrying the request ID. An inbound ID is reused,
+// otherwise one is generated by requestid.Generator, and the ID is set on the response.
+// Default is X-Request-ID.
+func WithRequestIDHeader(name string) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.requestIDHeader = name
+	})
+}
+
+// WithAccessLog also writes an Apache/NCSA combined format line per request to w, e.g. a
+// rotated access.log read by legacy tooling. Skipped paths are not written.
+func WithAccessLog(w io.Writer) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.accessLog = w
+	})
+}
+
+// WithDebugToken lowers the request logger to debug for requests carrying a valid debug
+// token for secret in the X-Debug-Token header, see logger.NewDebugToken. Requests whose
+// context was returned by logger.WithDebug get a debug logger regardless.
+func WithDebugToken(secret []byte) HandlerOption {
+	return optionFunc(func(cfg *config) {
+		cfg.debugSecret = secret
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level:           zerolog.InfoLevel,
+		routes:          make(map[string]zerolog.Level),
+		skip:            make(map[string]bool),
+		requestIDHeader: requestid.Header,
+	}
+}
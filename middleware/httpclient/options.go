@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type TransportOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	level          zerolog.Level
+	retries        int
+	backoff        time.Duration
+	headers        bool
+	redacted       map[string]struct{}
+	bodySampleRate float64
+	bodyLimit      int
+}
+
+// WithLevel sets the level of successful requests. Default is Info.
+func WithLevel(level zerolog.Level) TransportOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = level
+	})
+}
+
+// WithRetry retries idempotent requests up to retries times on network errors and 502,
+// 503 and 504 responses, waiting backoff times the attempt number between attempts.
+// Each retry is logged at Warn level. Default is no retry.
+func WithRetry(retries int, backoff time.Duration) TransportOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retries = retries
+		cfg.backoff = backoff
+	})
+}
+
+// WithHeaders logs the request and response headers. The values of Authorization,
+// Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key, plus the redacted names, are
+// replaced by Redacted.
+func WithHeaders(redacted ...string) TransportOption {
+	return optionFunc(func(cfg *config) {
+		cfg.headers = true
+		for _, name := range redacted {
+			cfg.redacted[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	})
+}
+
+// WithBodyCapture logs the first limit bytes of the request and response bodies for a
+// sampleRate fraction of the requests, from 0 to 1. Request bodies are only captured when
+// they can be read again, see http.Request.GetBody, and come with their size and
+// truncation, see logger.Body.
+func WithBodyCapture(sampleRate float64, limit int) TransportOption {
+	return optionFunc(func(cfg *config) {
+		cfg.bodySampleRate = sampleRate
+		cfg.bodyLimit = limit
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level: zerolog.InfoLevel,
+		redacted: map[string]struct{}{
+			"Authorization":       {},
+			"Proxy-Authorization": {},
+			"Cookie":              {},
+			"Set-Cookie":          {},
+			"X-Api-Key":           {},
+		},
+	}
+}
@@ -0,0 +1,229 @@
+// Package httpclient logs outbound HTTP requests.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/XiBao/logger"
+	httplog "github.com/XiBao/logger/middleware/http"
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the outbound request logs, on top of the middleware/http ones.
+const (
+	FieldRetries         = "retries"
+	FieldHeaders         = "headers"
+	FieldResponseHeaders = "response_headers"
+	FieldRequestBody     = "request_body"
+	FieldResponseBody    = "response_body"
+)
+
+// Redacted replaces the values of redacted headers.
+const Redacted = "[REDACTED]"
+
+var _ = http.RoundTripper(new(Transport))
+
+// Transport logs every request sent through the inner RoundTripper with its status and
+// latency: at Error level when it fails, Warn for 4xx and 5xx responses and the
+// configured level otherwise. The logger of the request context is used, with l as the
+// fallback for contexts without one. The request ID of the context, see package requestid,
+// is sent in the X-Request-ID header unless the request has one.
+type Transport struct {
+	inner http.RoundTripper
+	l     zerolog.Logger
+	cfg   config
+}
+
+// NewTransport creates a Transport sending requests with inner, http.DefaultTransport if nil.
+func NewTransport(inner http.RoundTripper, l zerolog.Logger, opts ...TransportOption) *Transport {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Transport{inner: inner, l: l, cfg: cfg}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := zerolog.Ctx(req.Context())
+	if l.GetLevel() == zerolog.Disabled {
+		l = &t.l
+	}
+	if id, ok := requestid.FromContext(req.Context()); ok && req.Header.Get(requestid.Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(requestid.Header, id)
+	}
+	capture := t.cfg.bodyLimit > 0 && rand.Float64() < t.cfg.bodySampleRate
+
+	start := time.Now()
+	var (
+		resp    *http.Response
+		err     error
+		retries int
+	)
+	for {
+		resp, err = t.inner.RoundTrip(req)
+		if retries >= t.cfg.retries || !t.retryable(req, resp, err) {
+			break
+		}
+		l.Warn().
+			Err(err).
+			Str(httplog.FieldMethod, req.Method).
+			Str(httplog.FieldURL, req.URL.Redacted()).
+			Int(httplog.FieldStatus, statusOf(resp)).
+			Int(FieldRetries, retries+1).
+			Msg("outbound request retried")
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		next, rerr := rewind(req)
+		if rerr != nil {
+			resp, err = nil, rerr
+			break
+		}
+		req = next
+		retries++
+		if !sleep(req.Context(), t.cfg.backoff*time.Duration(retries)) {
+			err = req.Context().Err()
+			resp = nil
+			break
+		}
+	}
+
+	level := t.cfg.level
+	switch {
+	case err != nil:
+		level = zerolog.ErrorLevel
+	case resp.StatusCode >= http.StatusBadRequest:
+		level = zerolog.WarnLevel
+	}
+	e := l.WithLevel(level)
+	if !e.Enabled() {
+		return resp, err
+	}
+	e = e.Err(err).
+		Str(httplog.FieldMethod, req.Method).
+		Str(httplog.FieldURL, req.URL.Redacted()).
+		Dur(httplog.FieldLatency, time.Since(start)).
+		Int(FieldRetries, retries)
+	if t.cfg.headers {
+		e = e.Dict(FieldHeaders, t.headers(req.Header))
+	}
+	if capture {
+		if body, ok := requestBody(req, t.cfg.bodyLimit); ok {
+			e = e.EmbedObject(body)
+		}
+	}
+	if resp != nil {
+		e = e.Int(httplog.FieldStatus, resp.StatusCode).
+			Int64(httplog.FieldBytes, resp.ContentLength)
+		if t.cfg.headers {
+			e = e.Dict(FieldResponseHeaders, t.headers(resp.Header))
+		}
+		if capture {
+			e = e.Str(FieldResponseBody, responseBody(resp, t.cfg.bodyLimit))
+		}
+	}
+	e.Msg("outbound request")
+	return resp, err
+}
+
+// retryable reports whether the attempt failed in a way worth retrying: a network error
+// or a 502, 503 or 504, for idempotent requests whose body can be sent again
+func (t *Transport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (t *Transport) headers(h http.Header) *zerolog.Event {
+	d := zerolog.Dict()
+	for name, values := range h {
+		if _, ok := t.cfg.redacted[http.CanonicalHeaderKey(name)]; ok {
+			d = d.Str(name, Redacted)
+			continue
+		}
+		d = d.Strs(name, values)
+	}
+	return d
+}
+
+// rewind returns a copy of req with a fresh body for another attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return req, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// requestBody captures the first limit bytes of a copy of the body, with its size
+func requestBody(req *http.Request, limit int) (zerolog.LogObjectMarshaler, bool) {
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close()
+	return logger.Body(FieldRequestBody, body, limit), true
+}
+
+// responseBody reads the first limit bytes of the body and puts them back in front of it
+func responseBody(resp *http.Response, limit int) string {
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return string(head)
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// Format is the encoding of the lines written by a logger created with New.
+type Format int
+
+const (
+	// JSON writes one JSON object per line, zerolog's own encoding.
+	JSON Format = iota
+	// Console writes colorized, aligned lines for humans, with stack traces on their own lines.
+	Console
+	// Logfmt writes key=value lines.
+	Logfmt
+	// CBOR writes binary CBOR maps, see github.com/XiBao/logger/writer/cbor to read them back.
+	CBOR
+)
+
+// Option configures a logger created with New.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+type config struct {
+	output   io.Writer
+	format   Format
+	minLevel zerolog.Level
+	noColor  bool
+	names    FieldNames
+	source   bool
+	caller   CallerFormat
+	gid      bool
+	theme    ConsoleTheme
+	icons    bool
+	framed   bool
+}
+
+func newDefaultConfig() config {
+	return config{
+		output:   os.Stderr,
+		format:   JSON,
+		minLevel: zerolog.TraceLevel,
+		caller:   DefaultCallerFormat,
+		theme:    DefaultConsoleTheme,
+	}
+}
+
+// WithOutput sets the writer the logger writes to. It defaults to os.Stderr.
+func WithOutput(w io.Writer) Option {
+	return optionFunc(func(c *config) {
+		c.output = w
+	})
+}
+
+// WithFormat sets the format of the lines. It defaults to JSON.
+func WithFormat(f Format) Option {
+	return optionFunc(func(c *config) {
+		c.format = f
+	})
+}
+
+// WithMinLevel sets the minimum level of the logger.
+func WithMinLevel(level zerolog.Level) Option {
+	return optionFunc(func(c *config) {
+		c.minLevel = level
+	})
+}
+
+// WithNoColor disables colors in the Console format. Colors are also disabled when
+// ColorDisabled reports so.
+func WithNoColor() Option {
+	return optionFunc(func(c *config) {
+		c.noColor = true
+	})
+}
+
+// WithFieldNames renames the error, message, timestamp and stack fields of this logger only,
+// e.g. FieldNames{Error: "err"}, unlike zerolog's global field name variables. Renaming
+// applies to the JSON, Logfmt and CBOR formats; the Console format keeps the global names.
+// Writers parsing lines, such as the Sentry writer, expect the global names, so use it on
+// the logger of the final output.
+func WithFieldNames(names FieldNames) Option {
+	return optionFunc(func(c *config) {
+		c.names = names
+	})
+}
+
+// WithSource adds the source location of every event: its file:line under the caller field,
+// in the format set by WithCallerFormat, and its function under the function field. It is off
+// by default.
+func WithSource(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.source = enabled
+	})
+}
+
+// WithCallerFormat sets the format of the caller field added by WithSource. It defaults to
+// DefaultCallerFormat. Unlike zerolog.CallerMarshalFunc it does not affect other loggers.
+func WithCallerFormat(format CallerFormat) Option {
+	return optionFunc(func(c *config) {
+		c.caller = format
+	})
+}
+
+// WithShortCaller is WithCallerFormat(ShortCaller(depth)).
+func WithShortCaller(depth int) Option {
+	return WithCallerFormat(ShortCaller(depth))
+}
+
+// WithGoroutineID adds the ID of the goroutine writing each event under the goroutine_id
+// field. Reading the ID costs a short stack trace per event, so use it while debugging
+// interleaved events rather than in production. GoroutineLogger reads it once per
+// goroutine instead.
+func WithGoroutineID() Option {
+	return optionFunc(func(c *config) {
+		c.gid = true
+	})
+}
+
+// WithConsoleTheme sets the colors of the Console format. It defaults to DefaultConsoleTheme.
+func WithConsoleTheme(theme ConsoleTheme) Option {
+	return optionFunc(func(c *config) {
+		c.theme = theme
+	})
+}
+
+// WithLevelIcons writes LevelIcons before the level labels of the Console format.
+func WithLevelIcons() Option {
+	return optionFunc(func(c *config) {
+		c.icons = true
+	})
+}
+
+// WithSingleLine writes every event as exactly one line, escaping the line breaks of the
+// Console stack traces, so container runtimes and collectors keep an event in one record.
+// See github.com/XiBao/logger/writer/frame to also split long lines.
+func WithSingleLine() Option {
+	return optionFunc(func(c *config) {
+		c.framed = true
+	})
+}
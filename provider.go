@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names of the built-in providers.
+const (
+	FieldGoroutines        = "goroutines"
+	FieldDeadlineRemaining = "deadline_remaining"
+)
+
+// Provider computes fields when an event is written rather than when the logger is built,
+// from the context of the event, set with Event.Ctx, or context.Background. It is a
+// zerolog.Hook, add it with Logger.Hook or WithProvider:
+//
+//	l = l.Hook(logger.Provider(func(ctx context.Context) map[string]interface{} {
+//		return map[string]interface{}{"flag_new_checkout": flags.Enabled(ctx, "new_checkout")}
+//	}))
+type Provider func(ctx context.Context) map[string]interface{}
+
+// implements zerolog.Hook
+func (p Provider) Run(e *zerolog.Event, level zerolog.Level, message string) {
+	if !e.Enabled() {
+		return
+	}
+	ctx := e.GetCtx()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if fields := p(ctx); len(fields) > 0 {
+		e.Fields(fields)
+	}
+}
+
+// WithProvider returns a logger with the fields of p added to each event.
+func WithProvider(p Provider) zerolog.Logger {
+	return LoggerHook.Hook(p)
+}
+
+// Goroutines provides the number of goroutines, use it as Provider(Goroutines).
+func Goroutines(context.Context) map[string]interface{} {
+	return map[string]interface{}{FieldGoroutines: runtime.NumGoroutine()}
+}
+
+// DeadlineRemaining provides the time left before the deadline of the event context, if
+// it has one. Use it as Provider(DeadlineRemaining).
+func DeadlineRemaining(ctx context.Context) map[string]interface{} {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{FieldDeadlineRemaining: time.Until(deadline)}
+}
@@ -0,0 +1,109 @@
+// Package requestid generates and propagates request IDs.
+//
+// The middlewares of this module reuse the ID of an inbound request, or generate one, store
+// it in the request context and echo it on the response. Outbound clients such as
+// middleware/httpclient and the gRPC client interceptors forward the ID of their context.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+)
+
+// Header is the HTTP header carrying the request ID; gRPC metadata uses its lower case.
+const Header = "X-Request-ID"
+
+// Field is the name of the request ID field.
+const Field = "request_id"
+
+// MaxLength is the maximum length of an inbound request ID. Longer IDs, or IDs with
+// characters other than printable ASCII, are replaced by a generated one.
+const MaxLength = 128
+
+// Generator generates the IDs of requests without one. Default is XID; set it to UUIDv7
+// at startup for UUIDs.
+var Generator = XID
+
+// XID returns a 20 character, time sortable ID, see github.com/rs/xid.
+func XID() string {
+	return xid.New().String()
+}
+
+// UUIDv7 returns a time sortable UUID version 7, as defined by RFC 9562.
+func UUIDv7() string {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(u[6:])
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// Resolve returns inbound when it is a usable request ID, and a new ID from Generator
+// otherwise.
+func Resolve(inbound string) string {
+	if valid(inbound) {
+		return inbound
+	}
+	return Generator()
+}
+
+func valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID of ctx.
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok && id != ""
+}
+
+var _ = zerolog.Hook(Hook{})
+
+// Hook adds the request ID of the event context, set with Event.Ctx, to the events of
+// loggers that do not come from a middleware, which already have the field:
+//
+//	log := logger.Hook(requestid.Hook{})
+//	log.Info().Ctx(ctx).Msg("charged")
+type Hook struct{}
+
+func (Hook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if id, ok := FromContext(e.GetCtx()); ok {
+		e.Str(Field, id)
+	}
+}
@@ -0,0 +1,64 @@
+package schema
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	eventField   string
+	common       map[string]bool
+	requireEvent bool
+	onViolation  func(Violation)
+	tb           TB
+}
+
+// WithEventField sets the field holding the event name. Default is "event_name".
+func WithEventField(field string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.eventField = field
+	})
+}
+
+// WithCommonFields allows fields on every event, on top of the level, time, message,
+// caller, error and stack fields, e.g. the request ID or service name set on the logger.
+func WithCommonFields(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		for _, f := range fields {
+			cfg.common[f] = true
+		}
+	})
+}
+
+// WithRequireRegistered reports events with a name missing from the registry. By default
+// only registered events are checked.
+func WithRequireRegistered() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.requireEvent = true
+	})
+}
+
+// WithViolationHandler is called with every violation. Default prints a warning to stderr.
+func WithViolationHandler(fn func(Violation)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onViolation = fn
+	})
+}
+
+// WithTB fails the test or benchmark tb on every violation, in addition to the handler.
+func WithTB(tb TB) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.tb = tb
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		eventField:  "event_name",
+		common:      make(map[string]bool),
+		onViolation: defaultViolationHandler,
+	}
+}
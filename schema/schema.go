@@ -0,0 +1,252 @@
+// Package schema keeps the fields of log events consistent across teams. Events are
+// identified by the value of their event name field; a Registry holds the expected fields
+// and types of each event, and Writer reports the lines breaking them. It is meant for
+// development and CI, where a violation can fail the tests:
+//
+//	reg := schema.NewRegistry()
+//	reg.MustRegister("order_placed", schema.Fields{"order_id": schema.String, "amount": schema.Number})
+//	log := zerolog.New(schema.NewWriter(os.Stderr, reg, schema.WithTB(t)))
+package schema
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/XiBao/logger/common"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Type is the type of a field.
+type Type int
+
+const (
+	// Any accepts every value.
+	Any Type = iota
+	String
+	Number
+	Bool
+	// Time accepts the timestamps zerolog writes, strings or numbers.
+	Time
+	Object
+	Array
+)
+
+var typeNames = [...]string{"any", "string", "number", "bool", "time", "object", "array"}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return "unknown"
+	}
+	return typeNames[t]
+}
+
+// Fields are the expected fields of an event and their types.
+type Fields map[string]Type
+
+// Registry holds the fields of the registered events. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	events map[string]Fields
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[string]Fields)}
+}
+
+// Register sets the fields of event. Registering an event twice is an error, so two
+// teams cannot silently define the same event differently.
+func (r *Registry) Register(event string, fields Fields) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[event]; ok {
+		return fmt.Errorf("schema: event %q already registered", event)
+	}
+	copied := make(Fields, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	r.events[event] = copied
+	return nil
+}
+
+// MustRegister is Register panicking on error, for package initialization.
+func (r *Registry) MustRegister(event string, fields Fields) {
+	if err := r.Register(event, fields); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the fields of event.
+func (r *Registry) Lookup(event string) (Fields, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fields, ok := r.events[event]
+	return fields, ok
+}
+
+// Kind is the kind of a Violation.
+type Kind int
+
+const (
+	// UnknownField is a field the event does not declare.
+	UnknownField Kind = iota
+	// WrongType is a field with a value of another type than declared.
+	WrongType
+	// UnregisteredEvent is an event missing from the registry, see WithRequireRegistered.
+	UnregisteredEvent
+)
+
+// Violation is a line breaking the schema of its event.
+type Violation struct {
+	Kind  Kind
+	Event string
+	Field string
+	Want  Type
+	Got   string
+}
+
+func (v Violation) String() string {
+	switch v.Kind {
+	case UnknownField:
+		return fmt.Sprintf("event %q: unknown field %q", v.Event, v.Field)
+	case WrongType:
+		return fmt.Sprintf("event %q: field %q is %s, want %s", v.Event, v.Field, v.Got, v.Want)
+	}
+	return fmt.Sprintf("event %q is not registered", v.Event)
+}
+
+// TB is the part of testing.TB used by WithTB.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer checks the lines of registered events against the registry before passing them,
+// unchanged, to the wrapped writer. Lines without an event name are not checked.
+type Writer struct {
+	next io.Writer
+	reg  *Registry
+	cfg  config
+}
+
+// NewWriter creates a Writer checking the lines written to next against reg.
+func NewWriter(next io.Writer, reg *Registry, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	for _, f := range []string{
+		cfg.eventField,
+		zerolog.LevelFieldName,
+		zerolog.TimestampFieldName,
+		zerolog.MessageFieldName,
+		zerolog.CallerFieldName,
+		zerolog.ErrorFieldName,
+		zerolog.ErrorStackFieldName,
+	} {
+		cfg.common[f] = true
+	}
+	return &Writer{next: next, reg: reg, cfg: cfg}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.check(p)
+	return w.next.Write(p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.check(p)
+	if lw, ok := w.next.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.next.Write(p)
+}
+
+func (w *Writer) check(p []byte) {
+	for _, v := range w.Check(p) {
+		if w.cfg.onViolation != nil {
+			w.cfg.onViolation(v)
+		}
+		if w.cfg.tb != nil {
+			w.cfg.tb.Helper()
+			w.cfg.tb.Errorf("schema: %s", v)
+		}
+	}
+}
+
+// Check returns the violations of an encoded line, sorted by field.
+func (w *Writer) Check(p []byte) []Violation {
+	name := gjson.GetBytes(p, common.EscapePath(w.cfg.eventField))
+	if name.Type != gjson.String {
+		return nil
+	}
+	event := name.String()
+	fields, ok := w.reg.Lookup(event)
+	if !ok {
+		if w.cfg.requireEvent {
+			return []Violation{{Kind: UnregisteredEvent, Event: event}}
+		}
+		return nil
+	}
+
+	var violations []Violation
+	gjson.ParseBytes(p).ForEach(func(key, value gjson.Result) bool {
+		field := key.String()
+		want, declared := fields[field]
+		switch {
+		case declared:
+			if !matches(want, value) {
+				violations = append(violations, Violation{Kind: WrongType, Event: event, Field: field, Want: want, Got: typeOf(value)})
+			}
+		case !w.cfg.common[field]:
+			violations = append(violations, Violation{Kind: UnknownField, Event: event, Field: field})
+		}
+		return true
+	})
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	return violations
+}
+
+func matches(want Type, value gjson.Result) bool {
+	switch want {
+	case String:
+		return value.Type == gjson.String
+	case Number:
+		return value.Type == gjson.Number
+	case Bool:
+		return value.IsBool()
+	case Time:
+		_, ok := common.ParseTime(value)
+		return ok
+	case Object:
+		return value.IsObject()
+	case Array:
+		return value.IsArray()
+	}
+	return true
+}
+
+func typeOf(value gjson.Result) string {
+	switch {
+	case value.IsObject():
+		return Object.String()
+	case value.IsArray():
+		return Array.String()
+	case value.IsBool():
+		return Bool.String()
+	}
+	return strings.ToLower(value.Type.String())
+}
+
+func defaultViolationHandler(v Violation) {
+	fmt.Fprintf(os.Stderr, "schema: %s\n", v)
+}
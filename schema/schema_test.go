@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func newTestRegistry() *Registry {
+	reg := NewRegistry()
+	reg.MustRegister("order_placed", Fields{
+		"order_id": String,
+		"amount":   Number,
+		"paid":     Bool,
+		"at":       Time,
+		"customer": Object,
+		"items":    Array,
+		"note":     Any,
+	})
+	return reg
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []WriterOption
+		line string
+		want []Violation
+	}{
+		{
+			name: "valid",
+			line: `{"level":"info","event_name":"order_placed","order_id":"o1","amount":9.5,"paid":true,"customer":{"id":1},"items":[1],"note":null,"message":"placed"}`,
+		},
+		{
+			name: "unknown field",
+			line: `{"event_name":"order_placed","order_id":"o1","coupon":"X"}`,
+			want: []Violation{{Kind: UnknownField, Event: "order_placed", Field: "coupon"}},
+		},
+		{
+			name: "common field",
+			opts: []WriterOption{WithCommonFields("request_id")},
+			line: `{"event_name":"order_placed","request_id":"r1"}`,
+		},
+		{
+			name: "wrong types sorted by field",
+			line: `{"event_name":"order_placed","paid":"yes","amount":"9.5","order_id":1,"customer":[],"items":{}}`,
+			want: []Violation{
+				{Kind: WrongType, Event: "order_placed", Field: "amount", Want: Number, Got: "string"},
+				{Kind: WrongType, Event: "order_placed", Field: "customer", Want: Object, Got: "array"},
+				{Kind: WrongType, Event: "order_placed", Field: "items", Want: Array, Got: "object"},
+				{Kind: WrongType, Event: "order_placed", Field: "order_id", Want: String, Got: "number"},
+				{Kind: WrongType, Event: "order_placed", Field: "paid", Want: Bool, Got: "string"},
+			},
+		},
+		{
+			name: "time as string",
+			line: `{"event_name":"order_placed","at":"2024-07-22T16:17:16Z"}`,
+		},
+		{
+			name: "time as number",
+			line: `{"event_name":"order_placed","at":1721665036}`,
+		},
+		{
+			name: "time not a timestamp",
+			line: `{"event_name":"order_placed","at":"yesterday"}`,
+			want: []Violation{{Kind: WrongType, Event: "order_placed", Field: "at", Want: Time, Got: "string"}},
+		},
+		{
+			name: "time as bool",
+			line: `{"event_name":"order_placed","at":true}`,
+			want: []Violation{{Kind: WrongType, Event: "order_placed", Field: "at", Want: Time, Got: "bool"}},
+		},
+		{
+			name: "no event name",
+			line: `{"anything":1}`,
+		},
+		{
+			name: "unregistered event",
+			line: `{"event_name":"order_shipped","anything":1}`,
+		},
+		{
+			name: "unregistered event required",
+			opts: []WriterOption{WithRequireRegistered()},
+			line: `{"event_name":"order_shipped","anything":1}`,
+			want: []Violation{{Kind: UnregisteredEvent, Event: "order_shipped"}},
+		},
+		{
+			name: "event field",
+			opts: []WriterOption{WithEventField("evt")},
+			line: `{"evt":"order_placed","event_name":"x"}`,
+			want: []Violation{{Kind: UnknownField, Event: "order_placed", Field: "event_name"}},
+		},
+	}
+	reg := newTestRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewWriter(io.Discard, reg, tt.opts...).Check([]byte(tt.line))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%s)\n got: %v\nwant: %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	reg := newTestRegistry()
+	if err := reg.Register("order_placed", Fields{}); err == nil {
+		t.Error("registering an event twice did not fail")
+	}
+}
+
+// fakeTB records the errors reported through WithTB
+type fakeTB struct {
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWriterTB(t *testing.T) {
+	tb := new(fakeTB)
+	var handled []Violation
+	w := NewWriter(io.Discard, newTestRegistry(), WithTB(tb), WithViolationHandler(func(v Violation) {
+		handled = append(handled, v)
+	}))
+	log := zerolog.New(w).With().Timestamp().Logger()
+
+	log.Info().Str("event_name", "order_placed").Str("order_id", "o1").Time("at", time.Now()).Msg("placed")
+	if len(tb.errors) != 0 {
+		t.Fatalf("valid event failed the test: %v", tb.errors)
+	}
+
+	log.Info().Str("event_name", "order_placed").Int("order_id", 1).Msg("placed")
+	want := `schema: event "order_placed": field "order_id" is number, want string`
+	if len(tb.errors) != 1 || tb.errors[0] != want {
+		t.Errorf("errors = %q, want [%q]", tb.errors, want)
+	}
+	if len(handled) != 1 || handled[0].Field != "order_id" {
+		t.Errorf("handled violations = %v, want the order_id one", handled)
+	}
+}
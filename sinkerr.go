@@ -0,0 +1,21 @@
+package logger
+
+import "github.com/XiBao/logger/internal/sinkerr"
+
+// SetSinkErrorHandler sets the handler invoked with the delivery errors of the network writers
+// (net, cloudwatch, amqp, elastic, kafka and loki) and of hook/alert that have no
+// WithErrorHandler of their own. sink is the name of the package. By default errors are
+// printed to stderr; a nil fn discards them.
+//
+// The gelf and syslog writers are not covered: they format lines for the writer they wrap
+// and return its errors from Write, to the zerolog.ErrorHandler. Wrapping the net writer,
+// their delivery errors are handled by it.
+func SetSinkErrorHandler(fn func(sink string, err error)) {
+	sinkerr.SetHandler(fn)
+}
+
+// SinkErrors returns the number of delivery errors per writer since the program started,
+// whether or not the writer has its own error handler.
+func SinkErrors() map[string]uint64 {
+	return sinkerr.Counts()
+}
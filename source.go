@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// FieldFunction is the field holding the function of the source location, next to the
+// zerolog.CallerFieldName field holding its file:line.
+const FieldFunction = "function"
+
+// sourceHook adds the source location of the event in its own format, leaving
+// zerolog.CallerMarshalFunc to other zerolog users of the program. Like zerolog's Caller,
+// it honors zerolog.CallerSkipFrameCount for wrappers. The functions of this package and
+// of zerolog logging on behalf of their caller, listed in wrapperFuncs, are skipped.
+type sourceHook struct {
+	format   CallerFormat
+	function bool
+}
+
+// wrapperFuncs are the functions which log on behalf of their caller, so the source hook
+// reports the caller of the outermost one.
+var wrapperFuncs = map[string]bool{
+	"github.com/XiBao/logger.Print":           true,
+	"github.com/XiBao/logger.Printf":          true,
+	"github.com/XiBao/logger.Emit":            true,
+	"github.com/XiBao/logger.ObserveFunc":     true,
+	"github.com/XiBao/logger.(*Timer).Stop":   true,
+	"github.com/XiBao/logger.(*Timer).stop":   true,
+	"github.com/rs/zerolog.(*Logger).Print":   true,
+	"github.com/rs/zerolog.(*Logger).Printf":  true,
+	"github.com/rs/zerolog.(*Logger).Println": true,
+	"github.com/rs/zerolog/log.Print":         true,
+	"github.com/rs/zerolog/log.Printf":        true,
+}
+
+// sourceDepth bounds the frames the source hook looks at, wrappers included
+const sourceDepth = 8
+
+func (h sourceHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	// Run is called by Event.msg, itself called by Msg, Msgf or Send
+	var pcs [sourceDepth]uintptr
+	n := runtime.Callers(zerolog.CallerSkipFrameCount+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			return
+		}
+		if wrapperFuncs[frame.Function] && more {
+			continue
+		}
+		e.Str(zerolog.CallerFieldName, h.format(frame.PC, frame.File, frame.Line))
+		if h.function && frame.Function != "" {
+			e.Str(FieldFunction, shortFunction(frame.Function))
+		}
+		return
+	}
+}
+
+// shortFunction trims the import path of the package from a function name,
+// e.g. "github.com/XiBao/logger.New" becomes "logger.New"
+func shortFunction(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/XiBao/logger/writer/cbor"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+var (
+	textContext = regexp.MustCompile(`\bg=(\d+)\b`)
+	textCheck   = regexp.MustCompile(`\bcheck=(\d+)\b`)
+)
+
+// stressLines returns the g and check fields of the lines written in format
+func stressLines(t *testing.T, format Format, out []byte) (g, check []string) {
+	t.Helper()
+	if format == CBOR {
+		var lines bytes.Buffer
+		if err := cbor.Decode(&lines, bytes.NewReader(out)); err != nil {
+			t.Fatalf("decode CBOR: %v", err)
+		}
+		out, format = lines.Bytes(), JSON
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if format == JSON {
+			var fields struct {
+				G     json.Number `json:"g"`
+				Check json.Number `json:"check"`
+			}
+			if err := json.Unmarshal(line, &fields); err != nil {
+				t.Fatalf("line %s: %v", line, err)
+			}
+			g, check = append(g, fields.G.String()), append(check, fields.Check.String())
+			continue
+		}
+		gm, cm := textContext.FindSubmatch(line), textCheck.FindSubmatch(line)
+		if gm == nil || cm == nil {
+			t.Fatalf("line %q lacks the g or check field", line)
+		}
+		g, check = append(g, string(gm[1])), append(check, string(cm[1]))
+	}
+	return g, check
+}
+
+// TestFormatsConcurrent logs from many goroutines mixing With, field methods, Msg and
+// Send, and checks every line carries the fields of its own goroutine, so events sharing
+// pooled buffers or context slices would be caught, especially under -race.
+func TestFormatsConcurrent(t *testing.T) {
+	const goroutines, events = 200, 20
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			var out syncBuffer
+			base := New(WithOutput(&out), WithFormat(f.format), WithNoColor()).
+				With().Str("service", "stress").Logger()
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					l := base.With().Int("g", g).Logger()
+					for i := 0; i < events; i++ {
+						switch i % 3 {
+						case 0:
+							l.Info().Int("check", g).Int("i", i).Msg("event")
+						case 1:
+							child := l.With().Strs("tags", []string{"a", strconv.Itoa(i)}).Logger()
+							child.Warn().Int("check", g).Send()
+						default:
+							tenFields(l.Debug()).Int("check", g).Msgf("event %d", i)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			gs, checks := stressLines(t, f.format, out.buf.Bytes())
+			if len(gs) != goroutines*events {
+				t.Fatalf("got %d lines, want %d", len(gs), goroutines*events)
+			}
+			for i := range gs {
+				if gs[i] != checks[i] {
+					t.Fatalf("line %d of goroutine %s carries the fields of goroutine %s", i, gs[i], checks[i])
+				}
+			}
+		})
+	}
+}
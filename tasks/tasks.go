@@ -0,0 +1,114 @@
+// Package tasks tags the logs of concurrent work with task IDs, so fan-out work can be
+// reconstructed from the logs: every task logs its own ID and the ID of the task that
+// spawned it.
+//
+//	g, ctx := tasks.WithContext(ctx)
+//	for _, shard := range shards {
+//		g.Go("sync-shard", func(ctx context.Context) error {
+//			zerolog.Ctx(ctx).Info().Msg("syncing") // with task_id, parent_task_id and task
+//			return sync(ctx, shard)
+//		})
+//	}
+//	err := g.Wait()
+package tasks
+
+import (
+	"context"
+
+	"github.com/XiBao/logger"
+	"github.com/XiBao/logger/requestid"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+)
+
+// Field names of the task fields.
+const (
+	FieldTaskID       = "task_id"
+	FieldParentTaskID = "parent_task_id"
+	FieldTaskName     = "task"
+)
+
+type ctxKey struct{}
+
+// task is the task of a context with the logger its children derive from
+type task struct {
+	id   string
+	base zerolog.Logger
+}
+
+// Start returns a copy of ctx for a new task named name, child of the task of ctx if any.
+// The logger of the returned context has the task fields. It is derived from the logger
+// of ctx when the root task started, or the global logger, so fields added to the logger
+// of a task are not inherited by its children.
+func Start(ctx context.Context, name string) context.Context {
+	parent, ok := ctx.Value(ctxKey{}).(task)
+	if !ok {
+		parent.base = *ctxLogger(ctx)
+	}
+	t := task{id: requestid.Generator(), base: parent.base}
+
+	c := t.base.With().Str(FieldTaskID, t.id)
+	if parent.id != "" {
+		c = c.Str(FieldParentTaskID, parent.id)
+	}
+	if name != "" {
+		c = c.Str(FieldTaskName, name)
+	}
+	l := c.Logger()
+	return l.WithContext(context.WithValue(ctx, ctxKey{}, t))
+}
+
+// ID returns the ID of the task of ctx, or an empty string.
+func ID(ctx context.Context) string {
+	t, _ := ctx.Value(ctxKey{}).(task)
+	return t.id
+}
+
+// ctxLogger returns the logger of ctx, or the global logger
+func ctxLogger(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return &logger.Logger
+}
+
+// Group is an errgroup.Group starting each goroutine as a child task of the context of
+// the group.
+type Group struct {
+	g   *errgroup.Group
+	ctx context.Context
+}
+
+// WithContext returns a Group and a context canceled when a goroutine of the group
+// returns an error or Wait returns, like errgroup.WithContext. The goroutines are children
+// of the task of ctx, if any.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	g, ctx := errgroup.WithContext(ctx)
+	return &Group{g: g, ctx: ctx}, ctx
+}
+
+// Go calls fn in a new goroutine with the context of a new task named name.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	ctx := Start(g.ctx, name)
+	g.g.Go(func() error {
+		return fn(ctx)
+	})
+}
+
+// TryGo is Go for groups with a limit; it reports whether the goroutine was started.
+func (g *Group) TryGo(name string, fn func(ctx context.Context) error) bool {
+	ctx := Start(g.ctx, name)
+	return g.g.TryGo(func() error {
+		return fn(ctx)
+	})
+}
+
+// SetLimit limits the number of active goroutines of the group, see errgroup.Group.SetLimit.
+func (g *Group) SetLimit(n int) {
+	g.g.SetLimit(n)
+}
+
+// Wait waits for the goroutines of the group and returns the first error.
+func (g *Group) Wait() error {
+	return g.g.Wait()
+}
@@ -0,0 +1,137 @@
+// Package testutil helps testing code which logs, and writers consuming log lines.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = zerolog.LevelWriter(new(CaptureWriter))
+
+// CaptureWriter stores the lines written to it. Use it as the output of a logger, or as
+// the next writer of the writer under test, and query the lines afterwards. It is safe for
+// concurrent use.
+type CaptureWriter struct {
+	mu    sync.Mutex
+	lines [][]byte
+}
+
+// NewCaptureWriter creates an empty CaptureWriter.
+func NewCaptureWriter() *CaptureWriter {
+	return new(CaptureWriter)
+}
+
+func (w *CaptureWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\n")
+	w.mu.Lock()
+	w.lines = append(w.lines, append([]byte(nil), line...))
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+// implements zerolog.LevelWriter
+func (w *CaptureWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+// Len returns the number of captured lines.
+func (w *CaptureWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.lines)
+}
+
+// Lines returns the captured lines without their trailing newline.
+func (w *CaptureWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := make([]string, len(w.lines))
+	for i, l := range w.lines {
+		lines[i] = string(l)
+	}
+	return lines
+}
+
+// Entries returns the captured lines decoded as JSON objects. Lines which are not JSON
+// objects are returned as nil.
+func (w *CaptureWriter) Entries() []map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entries := make([]map[string]interface{}, len(w.lines))
+	for i, l := range w.lines {
+		var entry map[string]interface{}
+		if json.Unmarshal(l, &entry) == nil {
+			entries[i] = entry
+		}
+	}
+	return entries
+}
+
+// FieldEquals reports whether a captured line has field equal to value, once both are
+// encoded to JSON, so FieldEquals("status", 200) matches "status":200. Nested fields are
+// addressed with dots, e.g. "event.action".
+func (w *CaptureWriter) FieldEquals(field string, value interface{}) bool {
+	return w.CountField(field, value) > 0
+}
+
+// CountField returns the number of captured lines with field equal to value, compared as
+// by FieldEquals.
+func (w *CaptureWriter) CountField(field string, value interface{}) int {
+	want, ok := normalize(value)
+	if !ok {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var n int
+	for _, l := range w.lines {
+		got := gjson.GetBytes(l, field)
+		if !got.Exists() {
+			continue
+		}
+		var v interface{}
+		if json.Unmarshal([]byte(got.Raw), &v) == nil && reflect.DeepEqual(v, want) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountLevel returns the number of captured lines of level.
+func (w *CaptureWriter) CountLevel(level zerolog.Level) int {
+	name := zerolog.LevelFieldMarshalFunc(level)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var n int
+	for _, l := range w.lines {
+		if gjson.GetBytes(l, zerolog.LevelFieldName).String() == name {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets the captured lines.
+func (w *CaptureWriter) Reset() {
+	w.mu.Lock()
+	w.lines = nil
+	w.mu.Unlock()
+}
+
+// normalize returns value as decoded from its JSON encoding
+func normalize(value interface{}) (interface{}, bool) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
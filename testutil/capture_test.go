@@ -0,0 +1,95 @@
+package testutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCaptureWriter(t *testing.T) {
+	w := NewCaptureWriter()
+	log := zerolog.New(w)
+	log.Info().Int("status", 200).Str("path", "/").Msg("served")
+	log.Error().Dict("event", zerolog.Dict().Str("action", "login")).Msg("failed")
+	log.Error().Int("status", 500).Msg("failed")
+
+	if got := w.Len(); got != 3 {
+		t.Fatalf("Len = %d, want 3", got)
+	}
+	if got, want := w.Lines()[0], `{"level":"info","status":200,"path":"/","message":"served"}`; got != want {
+		t.Errorf("Lines()[0] = %s, want %s", got, want)
+	}
+	if got := w.Entries()[2]["status"]; got != float64(500) {
+		t.Errorf("Entries()[2][status] = %v, want 500", got)
+	}
+	if !w.FieldEquals("status", 200) || w.FieldEquals("status", "200") {
+		t.Error("FieldEquals does not compare the JSON encodings")
+	}
+	if !w.FieldEquals("event.action", "login") {
+		t.Error("FieldEquals does not match nested fields")
+	}
+	if got := w.CountField("message", "failed"); got != 2 {
+		t.Errorf("CountField(message) = %d, want 2", got)
+	}
+	if got := w.CountLevel(zerolog.ErrorLevel); got != 2 {
+		t.Errorf("CountLevel(error) = %d, want 2", got)
+	}
+}
+
+func TestCaptureWriterNotJSON(t *testing.T) {
+	w := NewCaptureWriter()
+	if _, err := w.Write([]byte("plain text\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Lines()[0]; got != "plain text" {
+		t.Errorf("Lines()[0] = %q, want the line without its newline", got)
+	}
+	if got := w.Entries()[0]; got != nil {
+		t.Errorf("Entries()[0] = %v, want nil", got)
+	}
+}
+
+func TestCaptureWriterReset(t *testing.T) {
+	w := NewCaptureWriter()
+	log := zerolog.New(w)
+	log.Info().Msg("before")
+	w.Reset()
+	if got := w.Len(); got != 0 {
+		t.Fatalf("Len after Reset = %d, want 0", got)
+	}
+	log.Info().Msg("after")
+	if lines := w.Lines(); len(lines) != 1 || lines[0] != `{"level":"info","message":"after"}` {
+		t.Errorf("Lines after Reset = %v", lines)
+	}
+}
+
+func TestCaptureWriterConcurrent(t *testing.T) {
+	const goroutines, events = 16, 100
+	w := NewCaptureWriter()
+	log := zerolog.New(w)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < events; i++ {
+				log.Info().Int("g", g).Int("i", i).Msg("event")
+				if i%10 == 0 {
+					_ = w.Len()
+					_ = w.CountField("g", g)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if got := w.Len(); got != goroutines*events {
+		t.Fatalf("Len = %d, want %d", got, goroutines*events)
+	}
+	for g := 0; g < goroutines; g++ {
+		if got := w.CountField("g", g); got != events {
+			t.Errorf("CountField(g, %d) = %d, want %d", g, got, events)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names of the timer lines.
+const (
+	FieldTimer    = "timer"
+	FieldDuration = "duration"
+)
+
+// Timer measures the duration of an operation and logs it when stopped.
+type Timer struct {
+	l     *zerolog.Logger
+	name  string
+	level zerolog.Level
+	start time.Time
+}
+
+// NewTimer starts a Timer logging to l at Info level.
+func NewTimer(l *zerolog.Logger, name string) *Timer {
+	return &Timer{l: l, name: name, level: zerolog.InfoLevel, start: time.Now()}
+}
+
+// StartTimer starts a Timer logging to the global logger, with the caller of Stop.
+func StartTimer(name string) *Timer {
+	return NewTimer(&LoggerHook, name)
+}
+
+// Level sets the level of the line logged by Stop.
+func (t *Timer) Level(level zerolog.Level) *Timer {
+	t.level = level
+	return t
+}
+
+// Elapsed returns the time since the Timer started.
+func (t *Timer) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// Stop logs the timer name and duration with the extra fields, given as key-value pairs
+// or a map as accepted by Event.Fields, and returns the duration. The arguments of a
+// deferred call are evaluated at the defer statement, so fields known at the end of the
+// operation are passed in a closure:
+//
+//	t := logger.StartTimer("import")
+//	defer func() { t.Stop("rows", n) }()
+func (t *Timer) Stop(fields ...interface{}) time.Duration {
+	return t.stop(fields)
+}
+
+// stop is Stop, for the exported functions of the package, reporting their caller.
+func (t *Timer) stop(fields []interface{}) time.Duration {
+	d := t.Elapsed()
+	e := t.l.WithLevel(t.level)
+	if len(fields) == 1 {
+		e = e.Fields(fields[0])
+	} else if len(fields) > 1 {
+		e = e.Fields(fields)
+	}
+	e.Str(FieldTimer, t.name).Dur(FieldDuration, d).CallerSkipFrame(2).Msg(t.name + " done")
+	return d
+}
+
+// ObserveFunc runs fn and logs its duration to l under name, at Info level, or at Error
+// level with the error when fn fails. It returns the error of fn.
+func ObserveFunc(l *zerolog.Logger, name string, fn func() error) error {
+	t := NewTimer(l, name)
+	err := fn()
+	if err != nil {
+		t.level = zerolog.ErrorLevel
+		t.stop([]interface{}{zerolog.ErrorFieldName, err})
+		return err
+	}
+	t.stop(nil)
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package amqp publishes zerolog JSON lines to a RabbitMQ exchange.
+package amqp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/internal/sinkerr"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// ErrClosed is returned by writes after Close.
+var ErrClosed = errors.New("amqp: writer closed")
+
+// DefaultRoutingKey routes lines by level name: "info", "error", ...
+func DefaultRoutingKey(level zerolog.Level) string {
+	if level == zerolog.NoLevel {
+		return "log"
+	}
+	return level.String()
+}
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer publishes every log line as a persistent JSON message to an exchange, with a
+// routing key derived from the level, so that queues can bind to the levels they want.
+// The connection is opened on the first write and reopened after failures; lines that
+// cannot be published are reported to the error handler and dropped.
+type Writer struct {
+	url      string
+	exchange string
+	cfg      config
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	lastDial time.Time
+	closed   bool
+}
+
+// New creates a Writer publishing to exchange on the broker at url.
+func New(url, exchange string, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("amqp", cfg.onError)
+	return &Writer{url: url, exchange: exchange, cfg: cfg}
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	msg := amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         append([]byte(nil), bytes.TrimRight(p, "\n")...),
+	}
+	if w.cfg.appID != "" {
+		msg.AppId = w.cfg.appID
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if err := w.connect(); err != nil {
+		w.cfg.onError(err)
+		return len(p), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.publishTimeout)
+	defer cancel()
+	if err := w.ch.PublishWithContext(ctx, w.exchange, w.cfg.routingKey(level), false, false, msg); err != nil {
+		w.cfg.onError(err)
+		w.reset()
+	}
+	return len(p), nil
+}
+
+// connect opens the connection and channel if needed, at most once per reconnect interval
+func (w *Writer) connect() error {
+	if w.ch != nil && !w.ch.IsClosed() {
+		return nil
+	}
+	w.reset()
+	if time.Since(w.lastDial) < w.cfg.reconnectInterval {
+		return errors.New("amqp: broker unreachable, log line dropped")
+	}
+	w.lastDial = time.Now()
+	conn, err := amqp.DialConfig(w.url, w.cfg.amqpConfig)
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	w.conn, w.ch = conn, ch
+	return nil
+}
+
+func (w *Writer) reset() {
+	if w.ch != nil {
+		w.ch.Close()
+		w.ch = nil
+	}
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// Close closes the connection.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	w.reset()
+	return nil
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("amqp", err)
+}
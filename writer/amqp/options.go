@@ -0,0 +1,78 @@
+package amqp
+
+import (
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	routingKey        func(zerolog.Level) string
+	appID             string
+	amqpConfig        amqp.Config
+	publishTimeout    time.Duration
+	reconnectInterval time.Duration
+	onError           func(error)
+}
+
+// WithRoutingKey sets the function returning the routing key of a level.
+// Default is DefaultRoutingKey.
+func WithRoutingKey(fn func(zerolog.Level) string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.routingKey = fn
+	})
+}
+
+// WithAppID sets the app-id property of the messages.
+func WithAppID(id string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.appID = id
+	})
+}
+
+// WithConfig sets the connection configuration, e.g. TLS or the heartbeat.
+func WithConfig(c amqp.Config) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.amqpConfig = c
+	})
+}
+
+// WithPublishTimeout bounds each publish. Default is 5 seconds.
+func WithPublishTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.publishTimeout = timeout
+	})
+}
+
+// WithReconnectInterval sets the minimum delay between connection attempts. Default is 1 second.
+func WithReconnectInterval(interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.reconnectInterval = interval
+	})
+}
+
+// WithErrorHandler is called with connection and publish errors. Default prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		routingKey:        DefaultRoutingKey,
+		amqpConfig:        amqp.Config{Heartbeat: 10 * time.Second, Locale: "en_US"},
+		publishTimeout:    5 * time.Second,
+		reconnectInterval: time.Second,
+		onError:           defaultErrorHandler,
+	}
+}
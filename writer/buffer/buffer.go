@@ -0,0 +1,131 @@
+// Package buffer batches the lines written to an io.Writer, such as os.Stdout, to save a
+// system call per event.
+package buffer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer buffers lines and writes them to the wrapped writer when the buffer is full, on
+// every interval, after a line of the flush level or above, and on Flush and Close.
+// Lines are never split across writes.
+//
+//	w := buffer.New(os.Stdout)
+//	defer w.Close()
+//	log := zerolog.New(w)
+type Writer struct {
+	cfg  config
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu     sync.Mutex
+	buf    *bufio.Writer
+	closed bool
+}
+
+// New creates a Writer buffering the lines written to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	w := &Writer{
+		cfg:  cfg,
+		done: make(chan struct{}),
+		buf:  bufio.NewWriterSize(next, cfg.size),
+	}
+	if cfg.interval > 0 {
+		w.wg.Add(1)
+		go w.run()
+	}
+	return w
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if len(p) > w.buf.Available() && w.buf.Buffered() > 0 {
+		// keep the line in one write
+		if err := w.buf.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.buf.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if level >= w.cfg.flushLevel && level < zerolog.NoLevel {
+		err = w.buf.Flush()
+	}
+	return n, err
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+	t := time.NewTicker(w.cfg.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			_ = w.buf.Flush()
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Flush writes the buffered lines.
+func (w *Writer) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// Close stops the periodic flush and writes the buffered lines. It does not close the
+// wrapped writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
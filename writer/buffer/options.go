@@ -0,0 +1,52 @@
+package buffer
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	size       int
+	interval   time.Duration
+	flushLevel zerolog.Level
+}
+
+// WithSize sets the size of the buffer in bytes; it is flushed once full. Default is 64 KiB.
+func WithSize(size int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.size = size
+	})
+}
+
+// WithInterval sets how often buffered lines are flushed. Default is 1 second; 0 disables
+// the periodic flush.
+func WithInterval(interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.interval = interval
+	})
+}
+
+// WithFlushLevel flushes the buffer right after writing a line of level or above, so the
+// lines preceding a failure are not held back. Default is error; zerolog.Disabled turns it off.
+func WithFlushLevel(level zerolog.Level) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.flushLevel = level
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		size:       64 << 10,
+		interval:   time.Second,
+		flushLevel: zerolog.ErrorLevel,
+	}
+}
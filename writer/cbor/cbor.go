@@ -0,0 +1,146 @@
+// Package cbor converts zerolog JSON lines to CBOR and back.
+//
+// A logger built with the binary_log build tag encodes CBOR itself; Writer is for the
+// default build, where the lines are JSON. Either output is a sequence of CBOR maps that
+// Decoder turns back into JSON lines for inspection.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	majorUnsigned = 0 << 5
+	majorNegative = 1 << 5
+	majorBytes    = 2 << 5
+	majorText     = 3 << 5
+	majorArray    = 4 << 5
+	majorMap      = 5 << 5
+	majorTag      = 6 << 5
+	majorSimple   = 7 << 5
+)
+
+const (
+	simpleFalse   = majorSimple | 20
+	simpleTrue    = majorSimple | 21
+	simpleNull    = majorSimple | 22
+	simpleFloat64 = majorSimple | 27
+)
+
+var _ = io.Writer(new(Writer))
+
+// Writer converts each JSON line to a CBOR map and writes it to the wrapped writer.
+// Lines that are not JSON are written unchanged.
+type Writer struct {
+	next io.Writer
+	mu   sync.Mutex
+	buf  []byte
+}
+
+// New creates a Writer writing CBOR to next.
+func New(next io.Writer) *Writer {
+	return &Writer{next: next}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = Encode(w.buf[:0], p)
+	if _, err := w.next.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Encode appends the CBOR encoding of the JSON line to dst, keeping the field order.
+func Encode(dst []byte, p []byte) []byte {
+	line := bytes.TrimRight(p, "\n")
+	if !gjson.ValidBytes(line) {
+		return append(dst, p...)
+	}
+	return appendValue(dst, gjson.ParseBytes(line))
+}
+
+func appendValue(dst []byte, v gjson.Result) []byte {
+	switch v.Type {
+	case gjson.Null:
+		return append(dst, simpleNull)
+	case gjson.False:
+		return append(dst, simpleFalse)
+	case gjson.True:
+		return append(dst, simpleTrue)
+	case gjson.String:
+		return appendText(dst, v.String())
+	case gjson.Number:
+		return appendNumber(dst, v)
+	}
+	if v.IsArray() {
+		items := v.Array()
+		dst = appendHead(dst, majorArray, uint64(len(items)))
+		for _, item := range items {
+			dst = appendValue(dst, item)
+		}
+		return dst
+	}
+	var n uint64
+	v.ForEach(func(_, _ gjson.Result) bool {
+		n++
+		return true
+	})
+	dst = appendHead(dst, majorMap, n)
+	v.ForEach(func(key, value gjson.Result) bool {
+		dst = appendText(dst, key.String())
+		dst = appendValue(dst, value)
+		return true
+	})
+	return dst
+}
+
+func appendNumber(dst []byte, v gjson.Result) []byte {
+	if !strings.ContainsAny(v.Raw, ".eE") {
+		if strings.HasPrefix(v.Raw, "-") {
+			if n, err := strconv.ParseInt(v.Raw, 10, 64); err == nil {
+				return appendHead(dst, majorNegative, uint64(-(n + 1)))
+			}
+		} else if n, err := strconv.ParseUint(v.Raw, 10, 64); err == nil {
+			return appendHead(dst, majorUnsigned, n)
+		}
+	}
+	dst = append(dst, simpleFloat64)
+	return binary.BigEndian.AppendUint64(dst, math.Float64bits(v.Float()))
+}
+
+func appendText(dst []byte, s string) []byte {
+	dst = appendHead(dst, majorText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+}
@@ -0,0 +1,354 @@
+package cbor
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/XiBao/logger/internal/jsonenc"
+)
+
+const (
+	additionalIndefinite = 31
+	breakCode            = 0xff
+)
+
+const (
+	tagDateTime      = 0
+	tagEpoch         = 1
+	tagNetworkAddr   = 260
+	tagNetworkPrefix = 261
+	tagEmbeddedJSON  = 262
+	tagHexString     = 263
+)
+
+// ErrMalformed is returned by Decoder for input that is not CBOR.
+var ErrMalformed = errors.New("cbor: malformed input")
+
+// Decoder reads a sequence of CBOR values and returns them as JSON lines.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder creates a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next value as a JSON line ending with a newline.
+// It returns io.EOF when there are no more values.
+func (d *Decoder) Next() ([]byte, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	line, err := d.value(nil)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Decode writes the values read from src to dst as JSON lines.
+func Decode(dst io.Writer, src io.Reader) error {
+	dec := NewDecoder(src)
+	for {
+		line, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := dst.Write(line); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Decoder) head() (major byte, info byte, n uint64, err error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b&0xe0, b&0x1f
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == additionalIndefinite:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, ErrMalformed
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(d.r, buf[8-size:]); err != nil {
+		return 0, 0, 0, err
+	}
+	return major, info, binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func (d *Decoder) isBreak() (bool, error) {
+	b, err := d.r.Peek(1)
+	if err != nil {
+		return false, err
+	}
+	if b[0] != breakCode {
+		return false, nil
+	}
+	_, err = d.r.ReadByte()
+	return true, err
+}
+
+func (d *Decoder) value(dst []byte) ([]byte, error) {
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUnsigned:
+		return strconv.AppendUint(dst, n, 10), nil
+	case majorNegative:
+		if n > math.MaxInt64 {
+			return nil, ErrMalformed
+		}
+		return strconv.AppendInt(dst, -1-int64(n), 10), nil
+	case majorBytes:
+		b, err := d.bytes(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		return jsonenc.AppendString(dst, base64.StdEncoding.EncodeToString(b)), nil
+	case majorText:
+		b, err := d.bytes(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		return jsonenc.AppendString(dst, string(b)), nil
+	case majorArray:
+		dst = append(dst, '[')
+		for i := uint64(0); ; i++ {
+			if more, err := d.more(info, i, n); err != nil {
+				return nil, err
+			} else if !more {
+				break
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = d.value(dst); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case majorMap:
+		dst = append(dst, '{')
+		for i := uint64(0); ; i++ {
+			if more, err := d.more(info, i, n); err != nil {
+				return nil, err
+			} else if !more {
+				break
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = d.key(dst); err != nil {
+				return nil, err
+			}
+			dst = append(dst, ':')
+			if dst, err = d.value(dst); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case majorTag:
+		return d.tagged(dst, n)
+	}
+	return d.simple(dst, info, n)
+}
+
+// more reports whether the array or map has an item at index i
+func (d *Decoder) more(info byte, i, n uint64) (bool, error) {
+	if info != additionalIndefinite {
+		return i < n, nil
+	}
+	end, err := d.isBreak()
+	return !end, err
+}
+
+// key writes a map key, converting keys that are not text to strings
+func (d *Decoder) key(dst []byte) ([]byte, error) {
+	b, err := d.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0]&0xe0 == majorText {
+		return d.value(dst)
+	}
+	v, err := d.value(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(v) > 0 && v[0] == '"' {
+		return append(dst, v...), nil
+	}
+	return jsonenc.AppendString(dst, string(v)), nil
+}
+
+// bytes reads the content of a byte or text string, joining indefinite length chunks
+func (d *Decoder) bytes(major, info byte, n uint64) ([]byte, error) {
+	if info != additionalIndefinite {
+		if n > math.MaxInt32 {
+			return nil, ErrMalformed
+		}
+		b := make([]byte, n)
+		_, err := io.ReadFull(d.r, b)
+		return b, err
+	}
+	var out []byte
+	for {
+		end, err := d.isBreak()
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return out, nil
+		}
+		m, i, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || i == additionalIndefinite {
+			return nil, ErrMalformed
+		}
+		chunk, err := d.bytes(m, i, n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+}
+
+// tagged writes the tag content, formatting the tags zerolog uses in its binary encoding
+func (d *Decoder) tagged(dst []byte, tag uint64) ([]byte, error) {
+	switch tag {
+	case tagEpoch:
+		v, err := d.value(nil)
+		if err != nil {
+			return nil, err
+		}
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return append(dst, v...), nil
+		}
+		sec, frac := math.Modf(f)
+		t := time.Unix(int64(sec), int64(frac*float64(time.Second)))
+		return jsonenc.AppendString(dst, t.UTC().Format(time.RFC3339Nano)), nil
+	case tagEmbeddedJSON, tagNetworkAddr, tagHexString:
+		major, info, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != majorBytes && major != majorText {
+			return nil, ErrMalformed
+		}
+		b, err := d.bytes(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		switch tag {
+		case tagEmbeddedJSON:
+			return append(dst, b...), nil
+		case tagNetworkAddr:
+			return jsonenc.AppendString(dst, net.IP(b).String()), nil
+		}
+		return jsonenc.AppendString(dst, hex.EncodeToString(b)), nil
+	case tagNetworkPrefix:
+		major, _, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != majorMap || n != 1 {
+			return nil, ErrMalformed
+		}
+		major, info, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		ip, err := d.bytes(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		ones, err := d.value(nil)
+		if err != nil {
+			return nil, err
+		}
+		return jsonenc.AppendString(dst, net.IP(ip).String()+"/"+string(ones)), nil
+	}
+	// tagDateTime and unknown tags are written as their content
+	return d.value(dst)
+}
+
+func (d *Decoder) simple(dst []byte, info byte, n uint64) ([]byte, error) {
+	var f float64
+	switch info {
+	case 20:
+		return append(dst, "false"...), nil
+	case 21:
+		return append(dst, "true"...), nil
+	case 22, 23:
+		return append(dst, "null"...), nil
+	case 25:
+		f = halfToFloat(uint16(n))
+	case 26:
+		f = float64(math.Float32frombits(uint32(n)))
+	case 27:
+		f = math.Float64frombits(n)
+	default:
+		return nil, fmt.Errorf("%w: simple value %d", ErrMalformed, n)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return jsonenc.AppendString(dst, strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+	return strconv.AppendFloat(dst, f, 'g', -1, 64), nil
+}
+
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
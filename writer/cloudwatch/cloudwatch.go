@@ -0,0 +1,263 @@
+// Package cloudwatch ships zerolog JSON lines to AWS CloudWatch Logs.
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/XiBao/logger/writer/internal/batch"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// PutLogEvents limits
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	eventOverhead  = 26
+	maxBatchSpan   = 24 * time.Hour
+)
+
+// Client is the subset of *cloudwatchlogs.Client used by the writer.
+type Client interface {
+	PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer batches log lines into PutLogEvents calls to one log stream. The log group and
+// stream are created when missing, and throttled calls are retried with backoff.
+type Writer struct {
+	client  Client
+	group   string
+	stream  string
+	cfg     config
+	batcher *batch.Batcher
+
+	mu       sync.Mutex
+	sequence *string
+}
+
+// New creates a Writer shipping to the stream of the log group.
+func New(client Client, group, stream string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("cloudwatch", cfg.onError)
+	if client == nil || group == "" || stream == "" {
+		return nil, fmt.Errorf("cloudwatch: client, log group and stream are required")
+	}
+
+	w := &Writer{
+		client: client,
+		group:  group,
+		stream: stream,
+		cfg:    cfg,
+	}
+	w.batcher = batch.New(batch.Config{
+		MaxLines:    maxBatchEvents,
+		MaxBytes:    cfg.batchBytes,
+		Interval:    cfg.interval,
+		MaxBuffered: cfg.maxBuffered,
+		Retries:     cfg.retries,
+		Backoff:     cfg.backoff,
+		Send:        w.put,
+		OnError:     cfg.onError,
+	})
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\n")
+	if len(line)+eventOverhead > maxBatchBytes {
+		line = truncate(line, maxBatchBytes-eventOverhead)
+	}
+	w.batcher.Add(line)
+	return len(p), nil
+}
+
+// truncate cuts line to at most n bytes on a rune boundary, as CloudWatch rejects
+// events which are not valid UTF-8
+func truncate(line []byte, n int) []byte {
+	for i := n; i > 0 && i > n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(line[i]) {
+			return line[:i]
+		}
+	}
+	return line[:n]
+}
+
+// Flush ships the buffered lines and waits until they are sent or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	return w.batcher.Flush(ctx)
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *Writer) Dropped() uint64 {
+	return w.batcher.Dropped()
+}
+
+// Close ships the buffered lines and stops the writer.
+func (w *Writer) Close() error {
+	return w.batcher.Close()
+}
+
+// put sends a batch, split to respect the PutLogEvents limits. Only the lines of the
+// failed calls are returned for a retry.
+func (w *Writer) put(ctx context.Context, entries []batch.Entry) error {
+	events := make([]types.InputLogEvent, 0, len(entries))
+	for _, e := range entries {
+		ts := e.Time
+		if t, ok := common.ParseTime(gjson.GetBytes(e.Line, zerolog.TimestampFieldName)); ok {
+			ts = t
+		}
+		events = append(events, types.InputLogEvent{
+			Message:   aws.String(string(e.Line)),
+			Timestamp: aws.Int64(ts.UnixMilli()),
+		})
+	}
+	// events of a call must be in chronological order
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return *events[order[i]].Timestamp < *events[order[j]].Timestamp
+	})
+	sorted := make([]types.InputLogEvent, len(events))
+	for i, o := range order {
+		sorted[i] = events[o]
+	}
+
+	var failed, rejected []batch.Entry
+	var lastErr, rejectErr error
+	for start := 0; start < len(sorted); {
+		n, size := 0, 0
+		first := *sorted[start].Timestamp
+		for start+n < len(sorted) {
+			s := len(*sorted[start+n].Message) + eventOverhead
+			if size+s > maxBatchBytes || *sorted[start+n].Timestamp-first >= maxBatchSpan.Milliseconds() {
+				break
+			}
+			size += s
+			n++
+		}
+		if err := w.putEvents(ctx, sorted[start:start+n]); err != nil {
+			dst := &failed
+			if batch.IsPermanent(err) {
+				dst, rejectErr = &rejected, err
+			} else {
+				lastErr = err
+			}
+			for _, o := range order[start : start+n] {
+				*dst = append(*dst, entries[o])
+			}
+		}
+		start += n
+	}
+	if len(failed) > 0 {
+		if rejectErr != nil && w.cfg.onError != nil {
+			w.cfg.onError(rejectErr)
+		}
+		return batch.Partial(failed, lastErr)
+	}
+	if len(rejected) > 0 {
+		return batch.Partial(rejected, rejectErr)
+	}
+	return nil
+}
+
+func (w *Writer) putEvents(ctx context.Context, events []types.InputLogEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		out, err := w.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(w.group),
+			LogStreamName: aws.String(w.stream),
+			LogEvents:     events,
+			SequenceToken: w.sequence,
+		})
+		if err == nil {
+			w.sequence = out.NextSequenceToken
+			if info := out.RejectedLogEventsInfo; info != nil && w.cfg.onError != nil {
+				w.cfg.onError(fmt.Errorf("cloudwatch: log events rejected: %+v", *info))
+			}
+			return nil
+		}
+		if attempt > 0 {
+			return w.classify(err)
+		}
+
+		var invalidToken *types.InvalidSequenceTokenException
+		var accepted *types.DataAlreadyAcceptedException
+		var notFound *types.ResourceNotFoundException
+		switch {
+		case errors.As(err, &invalidToken):
+			w.sequence = invalidToken.ExpectedSequenceToken
+		case errors.As(err, &accepted):
+			w.sequence = accepted.ExpectedSequenceToken
+			return nil
+		case errors.As(err, &notFound) && w.cfg.create:
+			if err := w.createStream(ctx); err != nil {
+				return batch.Permanent(err)
+			}
+		default:
+			return w.classify(err)
+		}
+	}
+}
+
+// classify marks errors that retrying will not fix
+func (w *Writer) classify(err error) error {
+	var throttled *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	if errors.As(err, &throttled) || errors.As(err, &unavailable) {
+		return err
+	}
+	var invalid *types.InvalidParameterException
+	var denied *types.AccessDeniedException
+	if errors.As(err, &invalid) || errors.As(err, &denied) {
+		return batch.Permanent(err)
+	}
+	return err
+}
+
+// createStream creates the log group and stream, ignoring existing ones
+func (w *Writer) createStream(ctx context.Context) error {
+	var exists *types.ResourceAlreadyExistsException
+	_, err := w.client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(w.group),
+	})
+	if err != nil && !errors.As(err, &exists) {
+		return err
+	}
+	_, err = w.client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(w.group),
+		LogStreamName: aws.String(w.stream),
+	})
+	if err != nil && !errors.As(err, &exists) {
+		return err
+	}
+	w.sequence = nil
+	return nil
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("cloudwatch", err)
+}
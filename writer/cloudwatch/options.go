@@ -0,0 +1,73 @@
+package cloudwatch
+
+import "time"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	create      bool
+	batchBytes  int
+	interval    time.Duration
+	maxBuffered int
+	retries     int
+	backoff     time.Duration
+	onError     func(error)
+}
+
+// WithoutCreate disables the creation of missing log groups and streams.
+func WithoutCreate() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.create = false
+	})
+}
+
+// WithBatch sets the bytes that trigger a PutLogEvents call and the flush interval.
+// Defaults are 512 KiB and 5 seconds.
+func WithBatch(bytes int, interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.batchBytes = bytes
+		cfg.interval = interval
+	})
+}
+
+// WithMaxBuffered bounds the bytes held in memory. Lines written beyond it are dropped.
+// Default is 16 MiB.
+func WithMaxBuffered(bytes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxBuffered = bytes
+	})
+}
+
+// WithRetry sets how often a throttled or failed call is retried and the initial backoff,
+// doubled on every attempt. Defaults are 5 retries and 1 second.
+func WithRetry(retries int, backoff time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retries = retries
+		cfg.backoff = backoff
+	})
+}
+
+// WithErrorHandler is called with errors and dropped lines. Default prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		create:      true,
+		batchBytes:  512 << 10,
+		interval:    5 * time.Second,
+		maxBuffered: 16 << 20,
+		retries:     5,
+		backoff:     time.Second,
+		onError:     defaultErrorHandler,
+	}
+}
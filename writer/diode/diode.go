@@ -0,0 +1,49 @@
+// Package diode puts a non-blocking ring buffer in front of a writer, dropping lines
+// instead of blocking when the writer cannot keep up.
+package diode
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/diode"
+)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer is a many-writers/one-reader ring buffer in front of an io.Writer. Writes never
+// block; lines are dropped once the buffer is full and reported to the alert callback.
+type Writer struct {
+	diode   diode.Writer
+	dropped atomic.Uint64
+}
+
+// New creates a Writer draining into w from a background goroutine.
+func New(w io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	dw := new(Writer)
+	dw.diode = diode.NewWriter(w, cfg.size, cfg.pollInterval, func(missed int) {
+		dw.dropped.Add(uint64(missed))
+		if cfg.alert != nil {
+			cfg.alert(missed)
+		}
+	})
+	return dw
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.diode.Write(p)
+}
+
+// Dropped returns the number of lines dropped since the writer was created.
+func (w *Writer) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close drains the buffer and closes the wrapped writer if it is an io.Closer.
+func (w *Writer) Close() error {
+	return w.diode.Close()
+}
@@ -0,0 +1,47 @@
+package diode
+
+import "time"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	size         int
+	pollInterval time.Duration
+	alert        func(missed int)
+}
+
+// WithSize sets the number of lines the buffer holds. Default is 1000.
+func WithSize(size int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.size = size
+	})
+}
+
+// WithPollInterval drains the buffer on an interval instead of waking up on every write.
+// Polling costs less CPU under load at the price of latency. Default is 10 milliseconds,
+// zero waits for writes.
+func WithPollInterval(interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.pollInterval = interval
+	})
+}
+
+// WithAlert is called with the number of lines dropped whenever the buffer overflowed.
+func WithAlert(fn func(missed int)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.alert = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		size:         1000,
+		pollInterval: 10 * time.Millisecond,
+	}
+}
@@ -0,0 +1,150 @@
+// Package ecs maps zerolog JSON lines to the Elastic Common Schema.
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Version is the ECS version written to ecs.version.
+const Version = "8.11.0"
+
+// DefaultMapping maps the fields written by this package and its writers to ECS fields.
+// Dots in ECS names are nested objects.
+var DefaultMapping = map[string]string{
+	"error_code":  "error.code",
+	"error_type":  "error.type",
+	"trace_id":    "trace.id",
+	"span_id":     "span.id",
+	"request_id":  "http.request.id",
+	"http_method": "http.request.method",
+	"status":      "http.response.status_code",
+	"bytes":       "http.response.body.bytes",
+	"url":         "url.full",
+	"path":        "url.path",
+	"remote_ip":   "client.ip",
+	"user_agent":  "user_agent.original",
+	"user_id":     "user.id",
+	"host":        "host.name",
+	"service":     "service.name",
+	"logger":      "log.logger",
+}
+
+var _ = io.Writer(new(Writer))
+
+// Writer rewrites each line with ECS field names and writes it to the wrapped writer.
+// Fields without a mapping are kept under their name, or under the configured namespace.
+type Writer struct {
+	next    io.Writer
+	mapping map[string]string
+	ns      string
+	mu      sync.Mutex
+}
+
+// New creates a Writer writing ECS documents to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	mapping := map[string]string{
+		zerolog.LevelFieldName:      "log.level",
+		zerolog.MessageFieldName:    "message",
+		zerolog.ErrorFieldName:      "error.message",
+		zerolog.ErrorStackFieldName: "error.stack_trace",
+	}
+	for k, v := range cfg.mapping {
+		mapping[k] = v
+	}
+	return &Writer{next: next, mapping: mapping, ns: cfg.namespace}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	doc, err := w.Convert(p)
+	if err != nil {
+		doc = p
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.next.Write(doc); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Convert returns the ECS document of the encoded line.
+func (w *Writer) Convert(p []byte) ([]byte, error) {
+	line := bytes.TrimRight(p, "\n")
+	doc := map[string]interface{}{
+		"ecs": map[string]interface{}{"version": Version},
+	}
+	gjson.ParseBytes(line).ForEach(func(key, value gjson.Result) bool {
+		k := key.String()
+		switch k {
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				set(doc, "@timestamp", t.UTC().Format(time.RFC3339Nano))
+				return true
+			}
+		case zerolog.CallerFieldName:
+			caller := value.String()
+			if idx := strings.LastIndexByte(caller, ':'); idx > 0 {
+				set(doc, "log.origin.file.name", caller[:idx])
+				if n, err := strconv.Atoi(caller[idx+1:]); err == nil {
+					set(doc, "log.origin.file.line", n)
+				}
+				return true
+			}
+		}
+		name, ok := w.mapping[k]
+		if !ok {
+			// unmapped names are kept literally, their dots are not ECS paths
+			parent := doc
+			if w.ns != "" {
+				parent = object(doc, w.ns)
+			}
+			parent[k] = json.RawMessage(value.Raw)
+			return true
+		}
+		set(doc, name, json.RawMessage(value.Raw))
+		return true
+	})
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, p[len(line):]...), nil
+}
+
+// set stores v at the dotted ECS path of doc
+func set(doc map[string]interface{}, path string, v interface{}) {
+	idx := strings.LastIndexByte(path, '.')
+	if idx < 0 {
+		doc[path] = v
+		return
+	}
+	object(doc, path[:idx])[path[idx+1:]] = v
+}
+
+// object returns the nested object at the dotted path of doc, creating it if needed
+func object(doc map[string]interface{}, path string) map[string]interface{} {
+	m := doc
+	for _, part := range strings.Split(path, ".") {
+		child, ok := m[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[part] = child
+		}
+		m = child
+	}
+	return m
+}
@@ -0,0 +1,39 @@
+package ecs
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	mapping   map[string]string
+	namespace string
+}
+
+// WithMapping adds or overrides field to ECS name mappings.
+func WithMapping(mapping map[string]string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		for k, v := range mapping {
+			cfg.mapping[k] = v
+		}
+	})
+}
+
+// WithNamespace nests unmapped fields under namespace (e.g. "labels" or "app") instead of
+// keeping them at the top level, where they could clash with ECS fields.
+func WithNamespace(namespace string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.namespace = namespace
+	})
+}
+
+func newDefaultConfig() config {
+	mapping := make(map[string]string, len(DefaultMapping))
+	for k, v := range DefaultMapping {
+		mapping[k] = v
+	}
+	return config{mapping: mapping}
+}
@@ -0,0 +1,211 @@
+// Package elastic ships zerolog JSON lines to Elasticsearch or OpenSearch through the _bulk API.
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/XiBao/logger/writer/internal/batch"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer buffers log lines and indexes them in bulk requests.
+//
+// The index name is a template where the part in braces is a Go time layout applied to the
+// event time, e.g. "logs-{2006.01.02}" writes to daily indices. Lines that cannot be
+// indexed are appended to the dead-letter file, if configured.
+type Writer struct {
+	url     string
+	cfg     config
+	batcher *batch.Batcher
+	dlMu    sync.Mutex
+}
+
+// New creates a Writer indexing into the cluster at url (e.g. http://localhost:9200).
+func New(url string, index string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("elastic", cfg.onError)
+	if url == "" || index == "" {
+		return nil, fmt.Errorf("elastic: url and index are required")
+	}
+	cfg.index = index
+
+	w := &Writer{
+		url: strings.TrimSuffix(url, "/") + "/_bulk",
+		cfg: cfg,
+	}
+	w.batcher = batch.New(batch.Config{
+		MaxLines:    cfg.batchLines,
+		MaxBytes:    cfg.batchBytes,
+		Interval:    cfg.interval,
+		MaxBuffered: cfg.maxBuffered,
+		Block:       cfg.block,
+		Retries:     cfg.retries,
+		Backoff:     cfg.backoff,
+		Send:        w.bulk,
+		OnError:     cfg.onError,
+		OnGiveUp:    w.deadLetter,
+	})
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.batcher.Add(bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
+
+// Flush indexes the buffered lines and waits until they are sent or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	return w.batcher.Flush(ctx)
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *Writer) Dropped() uint64 {
+	return w.batcher.Dropped()
+}
+
+// Close indexes the buffered lines and stops the writer.
+func (w *Writer) Close() error {
+	return w.batcher.Close()
+}
+
+// IndexName expands the index template for t.
+func IndexName(template string, t time.Time) string {
+	start := strings.IndexByte(template, '{')
+	end := strings.LastIndexByte(template, '}')
+	if start < 0 || end < start {
+		return template
+	}
+	return template[:start] + t.UTC().Format(template[start+1:end]) + template[end+1:]
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends one batch. Documents rejected as throttled (429) or on a server error are
+// returned for a retry, the others are dead-lettered.
+func (w *Writer) bulk(ctx context.Context, entries []batch.Entry) error {
+	var body bytes.Buffer
+	for _, e := range entries {
+		ts := e.Time
+		if t, ok := common.ParseTime(gjson.GetBytes(e.Line, zerolog.TimestampFieldName)); ok {
+			ts = t
+		}
+		action := map[string]map[string]string{
+			w.cfg.opType: {"_index": IndexName(w.cfg.index, ts)},
+		}
+		if err := json.NewEncoder(&body).Encode(action); err != nil {
+			return batch.Permanent(err)
+		}
+		body.Write(e.Line)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &body)
+	if err != nil {
+		return batch.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.cfg.username != "" {
+		req.SetBasicAuth(w.cfg.username, w.cfg.password)
+	}
+	for k, v := range w.cfg.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.cfg.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if retryable(resp.StatusCode) {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("elastic: bulk request failed with status %d", resp.StatusCode)
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return batch.Permanent(fmt.Errorf("elastic: bulk request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg)))
+	}
+
+	var res bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil || !res.Errors {
+		return nil
+	}
+	var failed, throttled []batch.Entry
+	var lastErr, lastThrottled string
+	for i, item := range res.Items {
+		for _, r := range item {
+			switch {
+			case r.Status < 300 || i >= len(entries):
+			case retryable(r.Status):
+				throttled = append(throttled, entries[i])
+				lastThrottled = string(r.Error)
+			default:
+				failed = append(failed, entries[i])
+				lastErr = string(r.Error)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("elastic: %d of %d documents rejected: %s", len(failed), len(entries), lastErr)
+		if w.cfg.onError != nil {
+			w.cfg.onError(err)
+		}
+		w.deadLetter(failed, err)
+	}
+	if len(throttled) > 0 {
+		return batch.Partial(throttled, fmt.Errorf("elastic: %d of %d documents throttled: %s", len(throttled), len(entries), lastThrottled))
+	}
+	return nil
+}
+
+// retryable reports whether a document rejected with status may be indexed later
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status/100 == 5
+}
+
+// deadLetter appends lines that could not be indexed to the dead-letter file
+func (w *Writer) deadLetter(entries []batch.Entry, _ error) {
+	if w.cfg.deadLetter == "" {
+		return
+	}
+	w.dlMu.Lock()
+	defer w.dlMu.Unlock()
+	f, err := os.OpenFile(w.cfg.deadLetter, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		if w.cfg.onError != nil {
+			w.cfg.onError(err)
+		}
+		return
+	}
+	defer f.Close()
+	for _, e := range entries {
+		f.Write(e.Line)
+		f.Write([]byte{'\n'})
+	}
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("elastic", err)
+}
@@ -0,0 +1,123 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	index       string
+	opType      string
+	client      *http.Client
+	username    string
+	password    string
+	headers     map[string]string
+	batchLines  int
+	batchBytes  int
+	interval    time.Duration
+	maxBuffered int
+	block       bool
+	retries     int
+	backoff     time.Duration
+	deadLetter  string
+	onError     func(error)
+}
+
+// WithDataStream indexes with the "create" operation required by data streams.
+func WithDataStream() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.opType = "create"
+	})
+}
+
+// WithBasicAuth authenticates bulk requests.
+func WithBasicAuth(username, password string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.username = username
+		cfg.password = password
+	})
+}
+
+// WithHeader adds an HTTP header to bulk requests, e.g. an API key.
+func WithHeader(key, value string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.headers[key] = value
+	})
+}
+
+// WithHTTPClient sets the client used for bulk requests. Default has a 30 seconds timeout.
+func WithHTTPClient(client *http.Client) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.client = client
+	})
+}
+
+// WithBatch sets the number of lines and bytes that trigger a bulk request and the flush
+// interval. Defaults are 1000 lines, 5 MiB and 1 second.
+func WithBatch(lines int, bytes int, interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.batchLines = lines
+		cfg.batchBytes = bytes
+		cfg.interval = interval
+	})
+}
+
+// WithMaxBuffered bounds the bytes held in memory. Default is 32 MiB.
+func WithMaxBuffered(bytes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxBuffered = bytes
+	})
+}
+
+// WithBackpressure blocks writes while the buffer is full instead of dropping lines.
+func WithBackpressure() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.block = true
+	})
+}
+
+// WithRetry sets how often a failed bulk request is retried and the initial backoff, doubled
+// on every attempt. Defaults are 5 retries and 500 milliseconds.
+func WithRetry(retries int, backoff time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retries = retries
+		cfg.backoff = backoff
+	})
+}
+
+// WithDeadLetter appends lines that could not be indexed to the file at path.
+func WithDeadLetter(path string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.deadLetter = path
+	})
+}
+
+// WithErrorHandler is called with bulk errors and dropped lines. Default prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		opType:      "index",
+		client:      &http.Client{Timeout: 30 * time.Second},
+		headers:     make(map[string]string),
+		batchLines:  1000,
+		batchBytes:  5 << 20,
+		interval:    time.Second,
+		maxBuffered: 32 << 20,
+		retries:     5,
+		backoff:     500 * time.Millisecond,
+		onError:     defaultErrorHandler,
+	}
+}
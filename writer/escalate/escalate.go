@@ -0,0 +1,154 @@
+// Package escalate turns repeated warnings into errors.
+package escalate
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/jsonenc"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Field names added to escalated lines.
+const (
+	FieldEscalatedFrom = "escalated_from"
+	FieldOccurrences   = "occurrences"
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+type counter struct {
+	start time.Time
+	count int
+}
+
+// Writer counts the events of the tracked level by key, their message or the value of a
+// field. When a key reaches the threshold within the window, the line reaching it is
+// written at the escalated level, with the original level in escalated_from and the count
+// in occurrences, so sinks filtering on errors, such as the Sentry writer, pick it up.
+// A key escalates at most once per window. Other lines are written unchanged.
+type Writer struct {
+	next io.Writer
+	cfg  config
+
+	mu     sync.Mutex
+	counts map[string]*counter
+}
+
+// New creates a Writer escalating lines written to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Writer{
+		next:   next,
+		cfg:    cfg,
+		counts: make(map[string]*counter),
+	}
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level == w.cfg.level {
+		if n, ok := w.track(p); ok {
+			p = escalate(p, level, w.cfg.to, n)
+			level = w.cfg.to
+			if w.cfg.alert != nil {
+				w.cfg.alert.Notify(level, p)
+			}
+		}
+	}
+	if lw, ok := w.next.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.next.Write(p)
+}
+
+// track counts the line and reports whether it reaches the threshold of its key
+func (w *Writer) track(p []byte) (int, bool) {
+	key := zerolog.MessageFieldName
+	if w.cfg.keyField != "" {
+		key = common.EscapePath(w.cfg.keyField)
+	}
+	k := gjson.GetBytes(p, key).String()
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, ok := w.counts[k]
+	if !ok || now.Sub(c.start) >= w.cfg.window {
+		if !ok && len(w.counts) >= w.cfg.maxKeys {
+			w.prune(now)
+			if len(w.counts) >= w.cfg.maxKeys {
+				return 0, false
+			}
+		}
+		c = &counter{start: now}
+		w.counts[k] = c
+	}
+	c.count++
+	return c.count, c.count == w.cfg.threshold
+}
+
+// prune forgets the keys whose window has elapsed
+func (w *Writer) prune(now time.Time) {
+	for k, c := range w.counts {
+		if now.Sub(c.start) >= w.cfg.window {
+			delete(w.counts, k)
+		}
+	}
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// escalate rewrites the level of the encoded line and adds the escalation fields
+func escalate(p []byte, from, to zerolog.Level, n int) []byte {
+	line := bytes.TrimRight(p, "\n")
+	if len(line) == 0 || line[len(line)-1] != '}' {
+		return p
+	}
+	out := make([]byte, 0, len(p)+64)
+	lvl := gjson.GetBytes(line, zerolog.LevelFieldName)
+	if lvl.Exists() && lvl.Index > 0 {
+		out = append(out, line[:lvl.Index]...)
+		out = jsonenc.AppendString(out, zerolog.LevelFieldMarshalFunc(to))
+		out = append(out, line[lvl.Index+len(lvl.Raw):len(line)-1]...)
+	} else {
+		out = append(out, line[:len(line)-1]...)
+	}
+	if len(out) > 1 {
+		out = append(out, ',')
+	}
+	out = jsonenc.AppendString(out, FieldEscalatedFrom)
+	out = append(out, ':')
+	out = jsonenc.AppendString(out, zerolog.LevelFieldMarshalFunc(from))
+	out = append(out, ',')
+	out = jsonenc.AppendString(out, FieldOccurrences)
+	out = append(out, ':')
+	out = strconv.AppendInt(out, int64(n), 10)
+	out = append(out, '}')
+	return append(out, p[len(line):]...)
+}
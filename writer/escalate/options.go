@@ -0,0 +1,75 @@
+package escalate
+
+import (
+	"time"
+
+	"github.com/XiBao/logger/hook/alert"
+	"github.com/rs/zerolog"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	level     zerolog.Level
+	to        zerolog.Level
+	keyField  string
+	threshold int
+	window    time.Duration
+	maxKeys   int
+	alert     *alert.Hook
+}
+
+// WithLevels sets the level of the tracked events and the level they are escalated to.
+// Default is warn to error.
+func WithLevels(from, to zerolog.Level) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.level = from
+		cfg.to = to
+	})
+}
+
+// WithKeyField groups events by the value of field instead of their message.
+func WithKeyField(field string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.keyField = field
+	})
+}
+
+// WithThreshold escalates once count events of a key are seen within window. Default is
+// 10 events per minute.
+func WithThreshold(count int, window time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.threshold = count
+		cfg.window = window
+	})
+}
+
+// WithMaxKeys bounds the number of tracked keys. Default is 10000.
+func WithMaxKeys(n int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxKeys = n
+	})
+}
+
+// WithAlert sends escalated events to the alert hook as well.
+func WithAlert(h *alert.Hook) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.alert = h
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		level:     zerolog.WarnLevel,
+		to:        zerolog.ErrorLevel,
+		threshold: 10,
+		window:    time.Minute,
+		maxKeys:   10000,
+	}
+}
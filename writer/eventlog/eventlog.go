@@ -0,0 +1,91 @@
+// Package eventlog writes zerolog lines to the Windows Event Log, for programs running as
+// Windows services. On other systems New returns ErrUnsupported.
+package eventlog
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// ErrUnsupported is returned by New and Register on systems without an Event Log.
+var ErrUnsupported = errors.New("eventlog: the Windows Event Log is not available on this system")
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// handle is an open event source, *eventlog.Log on Windows
+type handle interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// Writer reports lines as events of an event source: trace, debug and info lines as
+// information events, warn lines as warnings, and error, fatal and panic lines as errors.
+// Lines without a level are information events. The event text is the JSON line.
+type Writer struct {
+	log handle
+	cfg config
+}
+
+// New creates a Writer reporting to the event source, usually the name of the service. The
+// source must be registered, by the installer of the service, with Register or WithRegister.
+func New(source string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if source == "" {
+		return nil, errors.New("eventlog: empty source")
+	}
+	if cfg.register {
+		if err := Register(source); err != nil {
+			return nil, err
+		}
+	}
+	log, err := open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{log: log, cfg: cfg}, nil
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.cfg.minLevel {
+		return len(p), nil
+	}
+	msg := string(bytes.TrimRight(p, "\n"))
+	var err error
+	switch level {
+	case zerolog.WarnLevel:
+		err = w.log.Warning(w.cfg.eventID, msg)
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel:
+		err = w.log.Error(w.cfg.eventID, msg)
+	default:
+		err = w.log.Info(w.cfg.eventID, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the event source.
+func (w *Writer) Close() error {
+	return w.log.Close()
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package eventlog
+
+func open(string) (handle, error) {
+	return nil, ErrUnsupported
+}
+
+// Register registers source as an event source. It returns ErrUnsupported on this system.
+func Register(string) error {
+	return ErrUnsupported
+}
+
+// Unregister removes the registration of source. It returns ErrUnsupported on this system.
+func Unregister(string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package eventlog
+
+import (
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+func open(source string) (handle, error) {
+	return eventlog.Open(source)
+}
+
+// Register registers source as an event source accepting information, warning and error
+// events, with the event message file of the system. It needs administrator rights, so it
+// is usually called by the installer of the service. Registering a registered source is
+// not an error.
+func Register(source string) error {
+	err := eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && isRegistered(source) {
+		return nil
+	}
+	return err
+}
+
+// Unregister removes the registration of source.
+func Unregister(source string) error {
+	return eventlog.Remove(source)
+}
+
+func isRegistered(source string) bool {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SYSTEM\CurrentControlSet\Services\EventLog\Application\`+source, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	k.Close()
+	return true
+}
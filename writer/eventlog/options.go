@@ -0,0 +1,46 @@
+package eventlog
+
+import "github.com/rs/zerolog"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	eventID  uint32
+	minLevel zerolog.Level
+	register bool
+}
+
+// WithEventID sets the event ID of the written events. Default is 1.
+func WithEventID(id uint32) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.eventID = id
+	})
+}
+
+// WithMinLevel drops lines below level. Default is info, so debug lines stay out of the
+// Event Log.
+func WithMinLevel(level zerolog.Level) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.minLevel = level
+	})
+}
+
+// WithRegister registers the event source when the writer is created, see Register.
+func WithRegister() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.register = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		eventID:  1,
+		minLevel: zerolog.InfoLevel,
+	}
+}
@@ -0,0 +1,110 @@
+// Package frame writes every log event as exactly one line, for container runtimes that
+// turn each line of stdout into a separate record.
+package frame
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer escapes the line breaks inside each write as \n and \r, so the multi-line stack
+// traces of the Console format stay in one record, and ends it with a single newline.
+// Each write is expected to hold one event, as written by zerolog.
+type Writer struct {
+	next io.Writer
+	cfg  config
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// New creates a Writer framing the lines written to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := config{}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.maxLine > 0 && cfg.maxLine <= len(cfg.marker)+utf8.UTFMax {
+		cfg.maxLine = len(cfg.marker) + utf8.UTFMax + 1
+	}
+	return &Writer{next: next, cfg: cfg}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = frame(w.buf[:0], p, w.cfg.maxLine, w.cfg.marker)
+	var err error
+	if lw, ok := w.next.(zerolog.LevelWriter); ok {
+		_, err = lw.WriteLevel(level, w.buf)
+	} else {
+		_, err = w.next.Write(w.buf)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// frame appends the escaped event to dst, split in lines of at most maxLine bytes
+func frame(dst, p []byte, maxLine int, marker string) []byte {
+	p = bytes.TrimRight(p, "\r\n")
+	start := len(dst)
+	for _, c := range p {
+		switch c {
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	if maxLine <= 0 || len(dst)-start < maxLine {
+		return append(dst, '\n')
+	}
+
+	line := append([]byte(nil), dst[start:]...)
+	dst = dst[:start]
+	chunk := maxLine - len(marker) - 1
+	for len(line) >= maxLine {
+		n := chunk
+		// do not cut a UTF-8 sequence or an escape
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		if n > 0 && line[n-1] == '\\' && backslashes(line[:n])%2 == 1 {
+			n--
+		}
+		if n == 0 {
+			n = chunk
+		}
+		dst = append(dst, line[:n]...)
+		dst = append(dst, marker...)
+		dst = append(dst, '\n')
+		line = line[n:]
+	}
+	dst = append(dst, line...)
+	return append(dst, '\n')
+}
+
+// backslashes counts the backslashes ending b
+func backslashes(b []byte) int {
+	n := 0
+	for i := len(b) - 1; i >= 0 && b[i] == '\\'; i-- {
+		n++
+	}
+	return n
+}
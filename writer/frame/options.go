@@ -0,0 +1,25 @@
+package frame
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	maxLine int
+	marker  string
+}
+
+// WithMaxLine splits lines longer than size bytes into lines of at most size bytes, each
+// but the last ending with marker, so a collector such as fluentd can join them back with
+// a multiline rule. It avoids the 16 KiB split of the Docker log drivers, which produces
+// records a collector cannot tell apart. Default is not to split lines.
+func WithMaxLine(size int, marker string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxLine = size
+		cfg.marker = marker
+	})
+}
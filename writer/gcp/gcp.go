@@ -0,0 +1,195 @@
+// Package gcp rewrites zerolog JSON lines into the structured logging schema of Google Cloud
+// Logging, so logs written to stdout on GKE or Cloud Run are parsed and trace-correlated.
+package gcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Special fields of the Cloud Logging structured logging schema.
+const (
+	FieldSeverity       = "severity"
+	FieldMessage        = "message"
+	FieldTime           = "time"
+	FieldTrace          = "logging.googleapis.com/trace"
+	FieldSpanID         = "logging.googleapis.com/spanId"
+	FieldTraceSampled   = "logging.googleapis.com/trace_sampled"
+	FieldSourceLocation = "logging.googleapis.com/sourceLocation"
+	FieldHTTPRequest    = "httpRequest"
+)
+
+// severities maps zerolog levels to Cloud Logging severities
+var severities = map[zerolog.Level]string{
+	zerolog.TraceLevel: "DEBUG",
+	zerolog.DebugLevel: "DEBUG",
+	zerolog.InfoLevel:  "INFO",
+	zerolog.WarnLevel:  "WARNING",
+	zerolog.ErrorLevel: "ERROR",
+	zerolog.FatalLevel: "CRITICAL",
+	zerolog.PanicLevel: "ALERT",
+	zerolog.NoLevel:    "DEFAULT",
+}
+
+var _ = io.Writer(new(Writer))
+
+// Writer rewrites each line and writes it to the wrapped writer, usually os.Stdout.
+type Writer struct {
+	next io.Writer
+	cfg  config
+	mu   sync.Mutex
+	buf  []byte
+}
+
+// New creates a Writer writing Cloud Logging entries to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Writer{next: next, cfg: cfg}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.Convert(w.buf[:0], p)
+	if _, err := w.next.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Convert appends the Cloud Logging entry of the encoded line to dst.
+func (w *Writer) Convert(dst []byte, p []byte) []byte {
+	line := bytes.TrimRight(p, "\n")
+	if !gjson.ValidBytes(line) {
+		return append(dst, p...)
+	}
+
+	e := &entry{dst: append(dst, '{')}
+	httpReq := make(map[string]interface{})
+	var traceID, spanID string
+	var sampled *bool
+	gjson.ParseBytes(line).ForEach(func(key, value gjson.Result) bool {
+		k := key.String()
+		switch k {
+		case zerolog.LevelFieldName:
+			level, err := zerolog.ParseLevel(value.String())
+			if err != nil {
+				level = zerolog.NoLevel
+			}
+			e.string(FieldSeverity, severities[level])
+		case zerolog.MessageFieldName:
+			e.raw(FieldMessage, value.Raw)
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				e.string(FieldTime, t.UTC().Format(time.RFC3339Nano))
+			} else {
+				e.raw(k, value.Raw)
+			}
+		case zerolog.CallerFieldName:
+			file, line := splitCaller(value.String())
+			e.value(FieldSourceLocation, map[string]string{"file": file, "line": line})
+		case w.cfg.traceField:
+			traceID = value.String()
+		case w.cfg.spanField:
+			spanID = value.String()
+		case w.cfg.sampledField:
+			b := value.Bool()
+			sampled = &b
+		case w.cfg.http.Method:
+			httpReq["requestMethod"] = value.String()
+		case w.cfg.http.URL:
+			httpReq["requestUrl"] = value.String()
+		case w.cfg.http.Status:
+			httpReq["status"] = value.Int()
+		case w.cfg.http.RemoteIP:
+			httpReq["remoteIp"] = value.String()
+		case w.cfg.http.UserAgent:
+			httpReq["userAgent"] = value.String()
+		case w.cfg.http.Latency:
+			// zerolog encodes durations as numbers in zerolog.DurationFieldUnit
+			d := time.Duration(value.Float() * float64(zerolog.DurationFieldUnit))
+			httpReq["latency"] = strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+		case w.cfg.http.ResponseSize:
+			httpReq["responseSize"] = strconv.FormatInt(value.Int(), 10)
+		default:
+			e.raw(k, value.Raw)
+		}
+		return true
+	})
+	if traceID != "" {
+		if w.cfg.projectID != "" && !strings.HasPrefix(traceID, "projects/") {
+			traceID = "projects/" + w.cfg.projectID + "/traces/" + traceID
+		}
+		e.string(FieldTrace, traceID)
+	}
+	if spanID != "" {
+		e.string(FieldSpanID, spanID)
+	}
+	if sampled != nil {
+		e.value(FieldTraceSampled, *sampled)
+	}
+	if len(httpReq) > 0 {
+		e.value(FieldHTTPRequest, httpReq)
+	}
+	dst = append(e.dst, '}')
+	return append(dst, p[len(line):]...)
+}
+
+// entry appends fields to an encoded JSON object
+type entry struct {
+	dst   []byte
+	count int
+}
+
+func (e *entry) key(k string) {
+	if e.count > 0 {
+		e.dst = append(e.dst, ',')
+	}
+	e.count++
+	e.dst = appendJSON(e.dst, k)
+	e.dst = append(e.dst, ':')
+}
+
+func (e *entry) raw(k string, raw string) {
+	e.key(k)
+	e.dst = append(e.dst, raw...)
+}
+
+func (e *entry) string(k string, v string) {
+	e.key(k)
+	e.dst = appendJSON(e.dst, v)
+}
+
+func (e *entry) value(k string, v interface{}) {
+	e.key(k)
+	e.dst = appendJSON(e.dst, v)
+}
+
+func appendJSON(dst []byte, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return append(dst, "null"...)
+	}
+	return append(dst, b...)
+}
+
+// splitCaller splits a file:line caller
+func splitCaller(caller string) (string, string) {
+	idx := strings.LastIndexByte(caller, ':')
+	if idx < 0 {
+		return caller, ""
+	}
+	return caller[:idx], caller[idx+1:]
+}
@@ -0,0 +1,70 @@
+package gcp
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+// HTTPFields names the fields mapped into the httpRequest of the entry.
+type HTTPFields struct {
+	Method       string
+	URL          string
+	Status       string
+	RemoteIP     string
+	UserAgent    string
+	Latency      string
+	ResponseSize string
+}
+
+type config struct {
+	projectID    string
+	traceField   string
+	spanField    string
+	sampledField string
+	http         HTTPFields
+}
+
+// WithProjectID qualifies trace IDs as projects/<id>/traces/<trace id>, as required for
+// Cloud Logging to link entries to Cloud Trace.
+func WithProjectID(id string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.projectID = id
+	})
+}
+
+// WithTraceFields sets the fields holding the trace ID, span ID and sampling decision.
+// Defaults are trace_id, span_id and trace_sampled.
+func WithTraceFields(trace, span, sampled string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.traceField = trace
+		cfg.spanField = span
+		cfg.sampledField = sampled
+	})
+}
+
+// WithHTTPFields sets the fields mapped into httpRequest.
+func WithHTTPFields(fields HTTPFields) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.http = fields
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		traceField:   "trace_id",
+		spanField:    "span_id",
+		sampledField: "trace_sampled",
+		http: HTTPFields{
+			Method:       "http_method",
+			URL:          "url",
+			Status:       "status",
+			RemoteIP:     "remote_ip",
+			UserAgent:    "user_agent",
+			Latency:      "latency",
+			ResponseSize: "bytes",
+		},
+	}
+}
@@ -0,0 +1,254 @@
+// Package gelf formats zerolog JSON lines as GELF 1.1 messages for Graylog.
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/jsonenc"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Version is the GELF version written to the version field.
+const Version = "1.1"
+
+// Compression is the compression of the messages sent over UDP.
+type Compression int
+
+const (
+	None Compression = iota
+	Zlib
+	Gzip
+)
+
+const (
+	chunkHeaderSize = 12
+	maxChunks       = 128
+)
+
+// ErrTooLarge is returned for messages needing more than 128 chunks.
+var ErrTooLarge = errors.New("gelf: message too large")
+
+// levels maps zerolog levels to the syslog severities used by GELF
+var levels = map[zerolog.Level]int{
+	zerolog.TraceLevel: 7,
+	zerolog.DebugLevel: 7,
+	zerolog.InfoLevel:  6,
+	zerolog.WarnLevel:  4,
+	zerolog.ErrorLevel: 3,
+	zerolog.FatalLevel: 2,
+	zerolog.PanicLevel: 0,
+	zerolog.NoLevel:    5,
+}
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer formats each line as a GELF message and writes it to the wrapped writer, e.g. a
+// writer/net Writer connected to a Graylog input.
+//
+// Over UDP every Write of the wrapped writer is a datagram: messages are compressed as
+// configured and split in GELF chunks when larger than the chunk size. Over TCP, see
+// WithTCPFraming, messages are uncompressed and terminated by a null byte.
+type Writer struct {
+	next io.Writer
+	cfg  config
+	mu   sync.Mutex
+	buf  []byte
+	zbuf bytes.Buffer
+}
+
+// New creates a Writer writing GELF messages to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Writer{next: next, cfg: cfg}
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.Format(w.buf[:0], level, p)
+	if err := w.send(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// send frames, compresses and chunks msg as configured
+func (w *Writer) send(msg []byte) error {
+	if w.cfg.tcp {
+		_, err := w.next.Write(append(msg, 0))
+		return err
+	}
+	if w.cfg.compression != None {
+		w.zbuf.Reset()
+		var zw io.WriteCloser
+		if w.cfg.compression == Gzip {
+			zw = gzip.NewWriter(&w.zbuf)
+		} else {
+			zw = zlib.NewWriter(&w.zbuf)
+		}
+		if _, err := zw.Write(msg); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		msg = w.zbuf.Bytes()
+	}
+	if len(msg) <= w.cfg.chunkSize {
+		_, err := w.next.Write(msg)
+		return err
+	}
+
+	size := w.cfg.chunkSize - chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > maxChunks {
+		return ErrTooLarge
+	}
+	chunk := make([]byte, 0, w.cfg.chunkSize)
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		end := min((i+1)*size, len(msg))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:end]...)
+		if _, err := w.next.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format appends the GELF message of the encoded line to dst. The message field is the
+// short message, the error stack the full message, and the other fields additional fields.
+func (w *Writer) Format(dst []byte, level zerolog.Level, p []byte) []byte {
+	severity, ok := levels[level]
+	if !ok {
+		severity = 5
+	}
+	res := gjson.ParseBytes(bytes.TrimRight(p, "\n"))
+
+	ts := time.Now()
+	short := res.Get(zerolog.MessageFieldName).String()
+	if short == "" {
+		short = res.Get(zerolog.ErrorFieldName).String()
+	}
+	if short == "" {
+		short = level.String()
+	}
+
+	dst = append(dst, `{"version":"`+Version+`","host":`...)
+	dst = jsonenc.AppendString(dst, w.cfg.host)
+	dst = append(dst, `,"short_message":`...)
+	dst = jsonenc.AppendString(dst, short)
+	if stack := res.Get(zerolog.ErrorStackFieldName); stack.Exists() {
+		dst = append(dst, `,"full_message":`...)
+		if stack.Type == gjson.String {
+			dst = jsonenc.AppendString(dst, stack.String())
+		} else {
+			dst = jsonenc.AppendString(dst, stack.Raw)
+		}
+	}
+	dst = append(dst, `,"level":`...)
+	dst = strconv.AppendInt(dst, int64(severity), 10)
+
+	res.ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.ErrorStackFieldName:
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				ts = t
+			}
+		default:
+			dst = appendField(dst, key.String(), value)
+		}
+		return true
+	})
+
+	dst = append(dst, `,"timestamp":`...)
+	dst = strconv.AppendFloat(dst, float64(ts.UnixMilli())/1e3, 'f', 3, 64)
+	return append(dst, '}')
+}
+
+// appendField writes an additional field, flattening objects with dotted names. GELF only
+// accepts strings and numbers, other values are written as their JSON.
+func appendField(dst []byte, key string, value gjson.Result) []byte {
+	if value.IsObject() {
+		value.ForEach(func(k, v gjson.Result) bool {
+			dst = appendField(dst, key+"."+k.String(), v)
+			return true
+		})
+		return dst
+	}
+	name := fieldName(key)
+	if name == "" {
+		return dst
+	}
+	dst = append(dst, ',', '"', '_')
+	dst = append(dst, name...)
+	dst = append(dst, '"', ':')
+	switch value.Type {
+	case gjson.Number:
+		return append(dst, value.Raw...)
+	case gjson.String:
+		return jsonenc.AppendString(dst, value.String())
+	}
+	return jsonenc.AppendString(dst, value.Raw)
+}
+
+// fieldName returns key with the characters GELF field names cannot hold replaced. The
+// reserved name "id" becomes "id_".
+func fieldName(key string) string {
+	if key == "id" {
+		return "id_"
+	}
+	b := []byte(key)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func defaultHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
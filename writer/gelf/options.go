@@ -0,0 +1,55 @@
+package gelf
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	host        string
+	compression Compression
+	chunkSize   int
+	tcp         bool
+}
+
+// WithHost sets the host field. Default is the host name of the machine.
+func WithHost(host string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.host = host
+	})
+}
+
+// WithCompression compresses the messages sent over UDP. Default is None.
+func WithCompression(compression Compression) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.compression = compression
+	})
+}
+
+// WithChunkSize sets the maximum datagram size over UDP, messages above are chunked.
+// Default is 1420 bytes, which fits the usual MTU.
+func WithChunkSize(size int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		if size > chunkHeaderSize {
+			cfg.chunkSize = size
+		}
+	})
+}
+
+// WithTCPFraming terminates the messages with a null byte, as expected by GELF TCP inputs,
+// instead of compressing and chunking them.
+func WithTCPFraming() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.tcp = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		host:      defaultHostname(),
+		chunkSize: 1420,
+	}
+}
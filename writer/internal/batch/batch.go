@@ -0,0 +1,262 @@
+// Package batch buffers log lines for writers shipping them in batches to remote services.
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrFull is reported when a line is dropped because the buffer is full.
+var ErrFull = errors.New("batch: buffer full, log line dropped")
+
+// Entry is a buffered log line with the time it was written.
+type Entry struct {
+	Time time.Time
+	Line []byte
+}
+
+// Config configures a Batcher.
+type Config struct {
+	// MaxLines flushes the batch once it holds that many lines.
+	MaxLines int
+	// MaxBytes flushes the batch once it holds that many bytes.
+	MaxBytes int
+	// Interval flushes the batch periodically.
+	Interval time.Duration
+	// MaxBuffered bounds the bytes held in memory, including the batch being sent.
+	// Lines written beyond it are dropped, or wait for space if Block is set.
+	MaxBuffered int
+	// Block makes Add wait for buffer space instead of dropping lines, applying
+	// backpressure to the logger.
+	Block bool
+	// Retries is the number of times a failed batch is resent.
+	Retries int
+	// Backoff is the initial delay between retries, doubled on every attempt.
+	Backoff time.Duration
+	// Send ships a batch. Errors are retried unless wrapped with Permanent, and only the
+	// entries of an error returned by Partial are resent.
+	Send func(ctx context.Context, entries []Entry) error
+	// OnError is called with errors of batches given up and with ErrFull.
+	OnError func(error)
+	// OnGiveUp is called with the entries of a batch given up after retries.
+	OnGiveUp func(entries []Entry, err error)
+}
+
+// Batcher buffers lines and sends them in batches from a background goroutine.
+type Batcher struct {
+	cfg      Config
+	mu       sync.Mutex
+	space    *sync.Cond
+	entries  []Entry
+	size     int
+	buffered int
+	dropped  atomic.Uint64
+	flushC   chan chan struct{}
+	closed   chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// New starts a Batcher.
+func New(cfg Config) *Batcher {
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = 1000
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 16 * cfg.MaxBytes
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	b := &Batcher{
+		cfg:    cfg,
+		flushC: make(chan chan struct{}, 1),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	b.space = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// Add copies line into the batch. It reports false if the line was dropped.
+func (b *Batcher) Add(line []byte) bool {
+	entry := Entry{Time: time.Now(), Line: append([]byte(nil), line...)}
+
+	b.mu.Lock()
+	for b.buffered > 0 && b.buffered+len(line) > b.cfg.MaxBuffered {
+		if !b.cfg.Block || b.isClosed() {
+			b.mu.Unlock()
+			b.dropped.Add(1)
+			b.report(ErrFull)
+			return false
+		}
+		b.space.Wait()
+	}
+	b.buffered += len(line)
+	b.entries = append(b.entries, entry)
+	b.size += len(line)
+	full := len(b.entries) >= b.cfg.MaxLines || b.size >= b.cfg.MaxBytes
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.flushC <- nil:
+		default:
+		}
+	}
+	return true
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (b *Batcher) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Flush sends the buffered lines and waits until they are shipped or ctx is done.
+func (b *Batcher) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case b.flushC <- done:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close sends the buffered lines and stops the background goroutine.
+func (b *Batcher) Close() error {
+	b.once.Do(func() {
+		close(b.closed)
+	})
+	<-b.done
+	b.mu.Lock()
+	b.space.Broadcast()
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *Batcher) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Batcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.send()
+		case done := <-b.flushC:
+			b.send()
+			if done != nil {
+				close(done)
+			}
+		case <-b.closed:
+			b.send()
+			return
+		}
+	}
+}
+
+// send ships the current batch, retrying with exponential backoff
+func (b *Batcher) send() {
+	b.mu.Lock()
+	entries, size := b.entries, b.size
+	b.entries, b.size = nil, 0
+	b.mu.Unlock()
+	if len(entries) == 0 {
+		return
+	}
+	defer func() {
+		b.mu.Lock()
+		b.buffered -= size
+		b.space.Broadcast()
+		b.mu.Unlock()
+	}()
+
+	backoff := b.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		err := b.cfg.Send(context.Background(), entries)
+		if err == nil {
+			return
+		}
+		var part partial
+		if errors.As(err, &part) {
+			entries = part.entries
+		}
+		if IsPermanent(err) || attempt >= b.cfg.Retries {
+			b.report(err)
+			if b.cfg.OnGiveUp != nil {
+				b.cfg.OnGiveUp(entries, err)
+			}
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-b.closed:
+			// keep retrying on close, but without waiting the full backoff
+		}
+		backoff *= 2
+	}
+}
+
+func (b *Batcher) report(err error) {
+	if b.cfg.OnError != nil {
+		b.cfg.OnError(err)
+	}
+}
+
+type permanent struct {
+	error
+}
+
+func (p permanent) Unwrap() error { return p.error }
+
+// Permanent marks err as not worth retrying.
+func Permanent(err error) error {
+	return permanent{err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var perm permanent
+	return errors.As(err, &perm)
+}
+
+type partial struct {
+	error
+	entries []Entry
+}
+
+func (p partial) Unwrap() error { return p.error }
+
+// Partial reports that entries of the batch failed with err and are to be resent, the
+// others having been shipped or handled by Send.
+func Partial(entries []Entry, err error) error {
+	return partial{err, entries}
+}
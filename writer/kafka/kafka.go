@@ -0,0 +1,108 @@
+// Package kafka publishes zerolog JSON lines to a Kafka topic.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/tidwall/gjson"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer publishes every log line as a record of a topic. Records are produced
+// asynchronously and batched by the Kafka client.
+type Writer struct {
+	topic   string
+	client  *kgo.Client
+	owned   bool
+	cfg     config
+	produce func(context.Context, *kgo.Record, func(*kgo.Record, error))
+}
+
+// New creates a Writer publishing to topic. Without WithClient a client is created from
+// WithBrokers and WithClientOptions and closed by Close.
+func New(topic string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("kafka: empty topic")
+	}
+
+	w := &Writer{
+		topic:  topic,
+		client: cfg.client,
+		cfg:    cfg,
+	}
+	if w.client == nil {
+		if len(cfg.brokers) == 0 {
+			return nil, fmt.Errorf("kafka: no brokers")
+		}
+		clientOpts := append([]kgo.Opt{kgo.SeedBrokers(cfg.brokers...)}, cfg.clientOpts...)
+		client, err := kgo.NewClient(clientOpts...)
+		if err != nil {
+			return nil, err
+		}
+		w.client = client
+		w.owned = true
+	}
+	w.produce = w.client.Produce
+	if cfg.nonBlocking {
+		w.produce = w.client.TryProduce
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	line := append([]byte(nil), bytes.TrimRight(p, "\n")...)
+	record := &kgo.Record{
+		Topic: w.topic,
+		Value: line,
+	}
+	if w.cfg.keyField != "" {
+		if key := gjson.GetBytes(line, common.EscapePath(w.cfg.keyField)); key.Exists() {
+			record.Key = []byte(key.String())
+		}
+	}
+	w.produce(context.Background(), record, w.delivered)
+	return len(p), nil
+}
+
+// delivered reports records that could not be produced
+func (w *Writer) delivered(r *kgo.Record, err error) {
+	if err == nil {
+		return
+	}
+	sinkerr.Inc("kafka")
+	if w.cfg.onError != nil {
+		w.cfg.onError(r.Value, err)
+	}
+}
+
+// Flush waits until the produced records are delivered or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	return w.client.Flush(ctx)
+}
+
+// Close flushes the produced records, waiting at most the configured flush timeout,
+// and closes the client if the writer created it.
+func (w *Writer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.flushTimeout)
+	defer cancel()
+	err := w.client.Flush(ctx)
+	if w.owned {
+		w.client.Close()
+	}
+	return err
+}
+
+func defaultErrorHandler(line []byte, err error) {
+	sinkerr.Handle("kafka", fmt.Errorf("could not deliver log line: %w", err))
+}
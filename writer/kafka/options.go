@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	client       *kgo.Client
+	brokers      []string
+	clientOpts   []kgo.Opt
+	keyField     string
+	nonBlocking  bool
+	flushTimeout time.Duration
+	onError      func(line []byte, err error)
+}
+
+// WithClient publishes through an existing client. The writer does not close it.
+func WithClient(client *kgo.Client) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.client = client
+	})
+}
+
+// WithBrokers sets the seed brokers of the client created by the writer.
+func WithBrokers(brokers ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.brokers = append(cfg.brokers, brokers...)
+	})
+}
+
+// WithClientOptions configures the client created by the writer, e.g. kgo.ProducerLinger
+// or kgo.MaxBufferedRecords to tune batching.
+func WithClientOptions(opts ...kgo.Opt) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.clientOpts = append(cfg.clientOpts, opts...)
+	})
+}
+
+// WithKeyField uses the value of field (e.g. request_id) as record key, so related lines
+// land in the same partition.
+func WithKeyField(field string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.keyField = field
+	})
+}
+
+// WithNonBlocking drops lines when the client buffer is full instead of blocking the logger.
+func WithNonBlocking() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.nonBlocking = true
+	})
+}
+
+// WithFlushTimeout sets how long Close waits for records to be delivered. Default is 5 seconds.
+func WithFlushTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.flushTimeout = timeout
+	})
+}
+
+// WithDeliveryErrorHandler is called with lines that could not be delivered.
+// Default prints to stderr.
+func WithDeliveryErrorHandler(fn func(line []byte, err error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		flushTimeout: 5 * time.Second,
+		onError:      defaultErrorHandler,
+	}
+}
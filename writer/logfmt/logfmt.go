@@ -0,0 +1,129 @@
+// Package logfmt converts zerolog JSON lines to logfmt.
+package logfmt
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = io.Writer(new(Writer))
+
+// Writer converts each line to logfmt and writes it to the wrapped writer.
+//
+// The time, level and message fields come first, in this order, followed by the other
+// fields in the order they were added to the event. Nested objects are flattened with
+// dotted keys, arrays are written as quoted JSON.
+type Writer struct {
+	next io.Writer
+	mu   sync.Mutex
+	buf  []byte
+}
+
+// New creates a Writer writing logfmt lines to next.
+func New(next io.Writer) *Writer {
+	return &Writer{next: next}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = Convert(w.buf[:0], p)
+	if _, err := w.next.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Convert appends the logfmt line of the encoded JSON line to dst.
+func Convert(dst []byte, p []byte) []byte {
+	line := bytes.TrimRight(p, "\n")
+	if !gjson.ValidBytes(line) {
+		return append(dst, p...)
+	}
+	res := gjson.ParseBytes(line)
+
+	start := len(dst)
+	for _, key := range [...]string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName} {
+		if v := res.Get(key); v.Exists() {
+			dst = appendPair(dst, start, key, v)
+		}
+	}
+	res.ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName:
+			return true
+		}
+		dst = appendPair(dst, start, key.String(), value)
+		return true
+	})
+	return append(dst, '\n')
+}
+
+func appendPair(dst []byte, start int, key string, value gjson.Result) []byte {
+	if value.IsObject() {
+		value.ForEach(func(k, v gjson.Result) bool {
+			dst = appendPair(dst, start, key+"."+k.String(), v)
+			return true
+		})
+		return dst
+	}
+	if len(dst) > start {
+		dst = append(dst, ' ')
+	}
+	dst = appendKey(dst, key)
+	dst = append(dst, '=')
+	switch value.Type {
+	case gjson.String:
+		return appendValue(dst, value.String())
+	case gjson.Null:
+		return dst
+	case gjson.JSON:
+		return appendValue(dst, value.Raw)
+	}
+	return append(dst, value.Raw...)
+}
+
+// appendKey writes key without the characters logfmt keys cannot hold
+func appendKey(dst []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c == '=' || c == '"' {
+			dst = append(dst, '_')
+			continue
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendValue writes s, quoted and escaped if needed
+func appendValue(dst []byte, s string) []byte {
+	if s != "" && !needsQuote(s) {
+		return append(dst, s...)
+	}
+	return strconv.AppendQuote(dst, s)
+}
+
+func needsQuote(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
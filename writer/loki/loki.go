@@ -0,0 +1,218 @@
+// Package loki ships zerolog JSON lines to Grafana Loki through its HTTP push API.
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/XiBao/logger/writer/internal/batch"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// PushPath is the path of the Loki push API.
+const PushPath = "/loki/api/v1/push"
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer batches log lines and pushes them to Loki. Streams are labeled with the static
+// labels and the values of the label fields of each line.
+type Writer struct {
+	url     string
+	cfg     config
+	batcher *batch.Batcher
+}
+
+// New creates a Writer pushing to the Loki instance at url (e.g. http://loki:3100).
+func New(url string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("loki", cfg.onError)
+	if url == "" {
+		return nil, fmt.Errorf("loki: empty url")
+	}
+
+	w := &Writer{
+		url: strings.TrimSuffix(url, "/") + PushPath,
+		cfg: cfg,
+	}
+	w.batcher = batch.New(batch.Config{
+		MaxLines:    cfg.batchLines,
+		MaxBytes:    cfg.batchBytes,
+		Interval:    cfg.interval,
+		MaxBuffered: cfg.maxBuffered,
+		Retries:     cfg.retries,
+		Backoff:     cfg.backoff,
+		Send:        w.push,
+		OnError:     cfg.onError,
+	})
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.batcher.Add(bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
+
+// Flush pushes the buffered lines and waits until they are sent or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	return w.batcher.Flush(ctx)
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *Writer) Dropped() uint64 {
+	return w.batcher.Dropped()
+}
+
+// Close pushes the buffered lines and stops the writer.
+func (w *Writer) Close() error {
+	return w.batcher.Close()
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type pushRequest struct {
+	Streams []*stream `json:"streams"`
+}
+
+// push sends one batch of lines grouped by their label sets
+func (w *Writer) push(ctx context.Context, entries []batch.Entry) error {
+	streams := make(map[string]*stream)
+	var order []string
+	for _, e := range entries {
+		labels, ts := w.labels(e)
+		key := labelsKey(labels)
+		s, ok := streams[key]
+		if !ok {
+			s = &stream{Stream: labels}
+			streams[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(e.Line)})
+	}
+	req := pushRequest{Streams: make([]*stream, 0, len(order))}
+	for _, key := range order {
+		req.Streams = append(req.Streams, streams[key])
+	}
+
+	var body bytes.Buffer
+	var enc io.Writer = &body
+	var gz *gzip.Writer
+	if w.cfg.gzip {
+		gz = gzip.NewWriter(&body)
+		enc = gz
+	}
+	if err := json.NewEncoder(enc).Encode(req); err != nil {
+		return batch.Permanent(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return batch.Permanent(err)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &body)
+	if err != nil {
+		return batch.Permanent(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if gz != nil {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	if w.cfg.tenant != "" {
+		httpReq.Header.Set("X-Scope-OrgID", w.cfg.tenant)
+	}
+	for k, v := range w.cfg.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := w.cfg.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("loki: push failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5 {
+		return err
+	}
+	return batch.Permanent(err)
+}
+
+// labels returns the label set and timestamp of a line
+func (w *Writer) labels(e batch.Entry) (map[string]string, time.Time) {
+	labels := make(map[string]string, len(w.cfg.labels)+len(w.cfg.labelFields)+1)
+	for k, v := range w.cfg.labels {
+		labels[k] = v
+	}
+	paths := make([]string, 0, len(w.cfg.labelFields)+2)
+	paths = append(paths, zerolog.LevelFieldName, zerolog.TimestampFieldName)
+	for _, f := range w.cfg.labelFields {
+		paths = append(paths, common.EscapePath(f))
+	}
+	res := gjson.GetManyBytes(e.Line, paths...)
+	if lvl := res[0].String(); lvl != "" {
+		labels["level"] = lvl
+	}
+	ts := e.Time
+	if t, ok := common.ParseTime(res[1]); ok {
+		ts = t
+	}
+	for i, f := range w.cfg.labelFields {
+		if v := res[i+2]; v.Exists() {
+			labels[labelName(f)] = v.String()
+		}
+	}
+	return labels, ts
+}
+
+// labelName sanitizes a field name into a valid Loki label name
+func labelName(field string) string {
+	b := []byte(field)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("loki", err)
+}
@@ -0,0 +1,125 @@
+package loki
+
+import (
+	"net/http"
+	"time"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	client      *http.Client
+	labels      map[string]string
+	labelFields []string
+	tenant      string
+	headers     map[string]string
+	gzip        bool
+	batchLines  int
+	batchBytes  int
+	interval    time.Duration
+	maxBuffered int
+	retries     int
+	backoff     time.Duration
+	onError     func(error)
+}
+
+// WithLabels sets static labels added to every stream.
+func WithLabels(labels map[string]string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		for k, v := range labels {
+			cfg.labels[k] = v
+		}
+	})
+}
+
+// WithLabelFields uses the values of the given fields as stream labels. Keep the number of
+// distinct values low, every label set is a separate Loki stream.
+func WithLabelFields(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.labelFields = append(cfg.labelFields, fields...)
+	})
+}
+
+// WithTenant sets the X-Scope-OrgID header for multi-tenant Loki.
+func WithTenant(tenant string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.tenant = tenant
+	})
+}
+
+// WithHeader adds an HTTP header to push requests, e.g. for authorization.
+func WithHeader(key, value string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.headers[key] = value
+	})
+}
+
+// WithHTTPClient sets the client used to push. Default has a 10 seconds timeout.
+func WithHTTPClient(client *http.Client) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.client = client
+	})
+}
+
+// WithoutGzip disables gzip compression of push requests.
+func WithoutGzip() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.gzip = false
+	})
+}
+
+// WithBatch sets the number of lines and bytes that trigger a push and the push interval.
+// Defaults are 1000 lines, 1 MiB and 1 second.
+func WithBatch(lines int, bytes int, interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.batchLines = lines
+		cfg.batchBytes = bytes
+		cfg.interval = interval
+	})
+}
+
+// WithMaxBuffered bounds the bytes held in memory. Lines written beyond it are dropped.
+// Default is 16 MiB.
+func WithMaxBuffered(bytes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxBuffered = bytes
+	})
+}
+
+// WithRetry sets how often a failed push is retried and the initial backoff, doubled on every
+// attempt. Defaults are 5 retries and 500 milliseconds.
+func WithRetry(retries int, backoff time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retries = retries
+		cfg.backoff = backoff
+	})
+}
+
+// WithErrorHandler is called with push errors and dropped lines. Default prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		labels:      make(map[string]string),
+		headers:     make(map[string]string),
+		gzip:        true,
+		batchLines:  1000,
+		batchBytes:  1 << 20,
+		interval:    time.Second,
+		maxBuffered: 16 << 20,
+		retries:     5,
+		backoff:     500 * time.Millisecond,
+		onError:     defaultErrorHandler,
+	}
+}
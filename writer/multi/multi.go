@@ -0,0 +1,83 @@
+// Package multi duplicates log lines to several writers, each with its own minimum level.
+package multi
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+type sink struct {
+	w     io.Writer
+	level zerolog.Level
+}
+
+// Writer writes each line to the writers whose minimum level is at or below the level of
+// the line, e.g. full debug to a file and warn and above to stdout. Lines without a level
+// are written to every writer.
+type Writer struct {
+	sinks []sink
+}
+
+// New creates a Writer from writers mapped to their minimum level.
+func New(writers map[io.Writer]zerolog.Level) *Writer {
+	w := &Writer{sinks: make([]sink, 0, len(writers))}
+	for out, level := range writers {
+		w.sinks = append(w.sinks, sink{w: out, level: level})
+	}
+	return w
+}
+
+// Add appends a writer with its minimum level. It is not safe to call concurrently with writes.
+func (w *Writer) Add(out io.Writer, level zerolog.Level) *Writer {
+	w.sinks = append(w.sinks, sink{w: out, level: level})
+	return w
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var errs []error
+	for _, s := range w.sinks {
+		if level != zerolog.NoLevel && level < s.level {
+			continue
+		}
+		var err error
+		if lw, ok := s.w.(zerolog.LevelWriter); ok {
+			_, err = lw.WriteLevel(level, p)
+		} else {
+			_, err = s.w.Write(p)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// Close closes every writer that is an io.Closer.
+func (w *Writer) Close() error {
+	var errs []error
+	for _, s := range w.sinks {
+		if c, ok := s.w.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,216 @@
+// Package net streams log lines to a remote collector over TCP, UDP or Unix sockets.
+package net
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/internal/sinkerr"
+)
+
+// ErrUnreachable is returned when a line could neither be sent nor spooled.
+var ErrUnreachable = errors.New("net: remote unreachable, log line dropped")
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer sends every log line to a remote address, reconnecting when the connection fails.
+// While the remote is unreachable, lines are appended to an on-disk spool, if configured,
+// and replayed once the connection is back.
+type Writer struct {
+	network string
+	addr    string
+	cfg     config
+
+	mu        sync.Mutex
+	conn      net.Conn
+	lastDial  time.Time
+	spoolSize int64
+	closed    bool
+}
+
+// New creates a Writer for network ("tcp", "udp", "unix", ...) and addr. The connection is
+// established on the first write.
+func New(network, addr string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("net", cfg.onError)
+	w := &Writer{
+		network: network,
+		addr:    addr,
+		cfg:     cfg,
+	}
+	if cfg.spool != "" {
+		if fi, err := os.Stat(cfg.spool); err == nil {
+			w.spoolSize = fi.Size()
+		}
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, net.ErrClosed
+	}
+
+	if err := w.connect(); err == nil {
+		if w.spoolSize > 0 {
+			w.replay()
+		}
+		if w.conn != nil {
+			if err := w.send(p); err == nil {
+				return len(p), nil
+			}
+		}
+	}
+	if err := w.toSpool(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// connect dials the remote if there is no connection, at most once per reconnect interval
+func (w *Writer) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	if time.Since(w.lastDial) < w.cfg.reconnect {
+		return ErrUnreachable
+	}
+	w.lastDial = time.Now()
+
+	dialer := &net.Dialer{Timeout: w.cfg.dialTimeout}
+	var conn net.Conn
+	var err error
+	if w.cfg.tls != nil {
+		conn, err = tls.DialWithDialer(dialer, w.network, w.addr, w.cfg.tls)
+	} else {
+		conn, err = dialer.Dial(w.network, w.addr)
+	}
+	if err != nil {
+		w.report(err)
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// send writes p with the write deadline, dropping the connection on failure
+func (w *Writer) send(p []byte) error {
+	if w.cfg.writeTimeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.writeTimeout))
+	}
+	if _, err := w.conn.Write(p); err != nil {
+		w.report(err)
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+// toSpool appends p to the spool file
+func (w *Writer) toSpool(p []byte) error {
+	if w.cfg.spool == "" || w.spoolSize+int64(len(p)) > w.cfg.spoolMax {
+		return ErrUnreachable
+	}
+	f, err := os.OpenFile(w.cfg.spool, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := f.Write(p)
+	w.spoolSize += int64(n)
+	return err
+}
+
+// replay sends the spooled lines and truncates the spool once they are all sent. When a
+// send fails, the spool is rewritten from the first line not sent.
+func (w *Writer) replay() {
+	f, err := os.Open(w.cfg.spool)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var sent int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if w.send(line) != nil {
+				w.trimSpool(f, sent)
+				return
+			}
+			sent += int64(len(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err := os.Truncate(w.cfg.spool, 0); err == nil {
+		w.spoolSize = 0
+	}
+}
+
+// trimSpool rewrites the spool f without its first sent bytes. The rest is copied to a
+// temporary file renamed over the spool, so a crash leaves either spool complete.
+func (w *Writer) trimSpool(f *os.File, sent int64) {
+	if sent == 0 {
+		return
+	}
+	if _, err := f.Seek(sent, io.SeekStart); err != nil {
+		w.report(err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.cfg.spool), filepath.Base(w.cfg.spool)+".*")
+	if err != nil {
+		w.report(err)
+		return
+	}
+	n, err := io.Copy(tmp, f)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), w.cfg.spool)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		w.report(err)
+		return
+	}
+	w.spoolSize = n
+}
+
+// Close closes the connection. Spooled lines are kept for the next Writer on the same spool.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *Writer) report(err error) {
+	if w.cfg.onError != nil {
+		w.cfg.onError(err)
+	}
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("net", err)
+}
@@ -0,0 +1,77 @@
+package net
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	tls          *tls.Config
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	reconnect    time.Duration
+	spool        string
+	spoolMax     int64
+	onError      func(error)
+}
+
+// WithTLS connects over TLS. Only stream networks such as tcp support TLS.
+func WithTLS(cfg *tls.Config) WriterOption {
+	return optionFunc(func(c *config) {
+		c.tls = cfg
+	})
+}
+
+// WithDialTimeout bounds connection attempts. Default is 5 seconds.
+func WithDialTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.dialTimeout = timeout
+	})
+}
+
+// WithWriteTimeout sets the write deadline of every line. Default is 5 seconds.
+func WithWriteTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.writeTimeout = timeout
+	})
+}
+
+// WithReconnectInterval sets the minimum delay between connection attempts. Default is 1 second.
+func WithReconnectInterval(interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.reconnect = interval
+	})
+}
+
+// WithSpool appends lines to the file at path while the remote is unreachable, up to
+// maxBytes, and replays them once connected again.
+func WithSpool(path string, maxBytes int64) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.spool = path
+		cfg.spoolMax = maxBytes
+	})
+}
+
+// WithErrorHandler is called with connection errors. Default prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		dialTimeout:  5 * time.Second,
+		writeTimeout: 5 * time.Second,
+		reconnect:    time.Second,
+		onError:      defaultErrorHandler,
+	}
+}
@@ -0,0 +1,97 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir is a Store keeping objects as files under a directory, e.g. a mounted network share.
+// Keys are slash separated paths relative to the directory.
+type Dir string
+
+var _ = Store(Dir(""))
+
+func (d Dir) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("objstore: invalid key %q", key)
+	}
+	return filepath.Join(string(d), clean), nil
+}
+
+// Put writes the object to a temporary file and renames it once its checksum is verified.
+func (d Dir) Put(_ context.Context, key string, r io.Reader, _ int64, sum []byte) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if sum != nil && !bytes.Equal(h.Sum(nil), sum) {
+		return fmt.Errorf("objstore: checksum mismatch for %q", key)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// List walks the directory for the objects with a key starting with prefix.
+func (d Dir) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(string(d), func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(string(d), path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, Modified: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+// Delete removes the file of the object.
+func (d Dir) Delete(_ context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
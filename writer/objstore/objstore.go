@@ -0,0 +1,377 @@
+// Package objstore archives log lines to object storage such as S3 or GCS. Lines are
+// written to local segment files which are uploaded, with their SHA-256 checksum, once
+// closed.
+package objstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/XiBao/logger/internal/sinkerr"
+)
+
+// DefaultKeyTemplate stores segments by host and day.
+const DefaultKeyTemplate = `logs/{{ .Host }}/{{ .Start.Format "2006/01/02" }}/{{ .Start.Format "150405" }}-{{ .ID }}{{ .Ext }}`
+
+// Store is the object storage segments are uploaded to. Implement it with the S3 or GCS
+// client of the program; Dir stores them in a local directory.
+type Store interface {
+	// Put uploads size bytes read from r under key. sum is the SHA-256 of the content,
+	// which the store should verify, e.g. as the ChecksumSHA256 of an S3 PutObject call.
+	Put(ctx context.Context, key string, r io.Reader, size int64, sum []byte) error
+	// List returns the objects with a key starting with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete removes the object of key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object is a stored object.
+type Object struct {
+	Key      string
+	Modified time.Time
+}
+
+// Segment is the data the key template is executed with.
+type Segment struct {
+	// Host is the host name of the machine.
+	Host string
+	// Start is the UTC time the segment was opened.
+	Start time.Time
+	// ID is unique to the segment on the host.
+	ID string
+	// Ext is ".log", or ".log.gz" with WithCompress.
+	Ext string
+}
+
+const (
+	activeSuffix = ".active"
+	retryEvery   = time.Minute
+)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer appends lines to a segment file of its directory. A segment is closed once it
+// reaches the size or age of WithSegment, and on Flush and Close, then uploaded in the
+// background and removed locally once stored. Segments left by a previous run are uploaded
+// too, so the directory should be dedicated to the writer.
+type Writer struct {
+	store Store
+	dir   string
+	host  string
+	key   *template.Template
+	cfg   config
+
+	mu     sync.Mutex
+	file   *os.File
+	gz     *gzip.Writer
+	id     string
+	start  time.Time
+	size   int64
+	closed bool
+
+	upload    sync.Mutex
+	lastPrune time.Time
+
+	kick chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a Writer keeping segments in dir and uploading them to store.
+func New(store Store, dir string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("objstore", cfg.onError)
+	if store == nil || dir == "" {
+		return nil, errors.New("objstore: store and directory are required")
+	}
+	key, err := template.New("key").Parse(cfg.key)
+	if err != nil {
+		return nil, fmt.Errorf("objstore: key template: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+
+	// segments active when a previous run stopped are closed now
+	active, _ := filepath.Glob(filepath.Join(dir, "*"+activeSuffix))
+	for _, name := range active {
+		_ = os.Rename(name, strings.TrimSuffix(name, activeSuffix))
+	}
+
+	w := &Writer{
+		store: store,
+		dir:   dir,
+		host:  host,
+		key:   key,
+		cfg:   cfg,
+		kick:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	w.trigger()
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if w.file != nil && (w.size >= w.cfg.maxSize || time.Since(w.start) >= w.cfg.maxAge) {
+		if err := w.roll(); err != nil {
+			return 0, err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	var n int
+	var err error
+	if w.gz != nil {
+		n, err = w.gz.Write(p)
+	} else {
+		n, err = w.file.Write(p)
+	}
+	w.size += int64(n)
+	return n, err
+}
+
+// open starts a new segment
+func (w *Writer) open() error {
+	now := time.Now().UTC()
+	id := strconv.FormatInt(now.UnixNano(), 36)
+	ext := ".log"
+	if w.cfg.compress {
+		ext += ".gz"
+	}
+	f, err := os.OpenFile(filepath.Join(w.dir, id+ext+activeSuffix), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file, w.id, w.start, w.size = f, id, now, 0
+	if w.cfg.compress {
+		w.gz = gzip.NewWriter(f)
+	}
+	return nil
+}
+
+// roll closes the current segment and triggers its upload
+func (w *Writer) roll() error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		err = w.gz.Close()
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	name := w.file.Name()
+	w.file, w.gz = nil, nil
+	if rerr := os.Rename(name, strings.TrimSuffix(name, activeSuffix)); err == nil {
+		err = rerr
+	}
+	w.trigger()
+	return err
+}
+
+func (w *Writer) trigger() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+	every := retryEvery
+	if w.cfg.maxAge < every {
+		every = w.cfg.maxAge
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.kick:
+		case <-t.C:
+			w.mu.Lock()
+			if w.file != nil && time.Since(w.start) >= w.cfg.maxAge {
+				if err := w.roll(); err != nil {
+					w.cfg.onError(err)
+				}
+			}
+			w.mu.Unlock()
+		}
+		_ = w.uploadAll(context.Background())
+	}
+}
+
+// uploadAll uploads the closed segments, oldest first, then applies the retention
+func (w *Writer) uploadAll(ctx context.Context) error {
+	w.upload.Lock()
+	defer w.upload.Unlock()
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		w.cfg.onError(err)
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasSuffix(e.Name(), activeSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.put(ctx, name); err != nil {
+			err = fmt.Errorf("objstore: upload %s: %w", name, err)
+			w.cfg.onError(err)
+			errs = append(errs, err)
+		}
+	}
+	if w.cfg.retention > 0 && time.Since(w.lastPrune) >= retryEvery {
+		w.lastPrune = time.Now()
+		if err := w.prune(ctx); err != nil {
+			err = fmt.Errorf("objstore: retention: %w", err)
+			w.cfg.onError(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// put uploads the segment file name and removes it once stored
+func (w *Writer) put(ctx context.Context, name string) error {
+	path := filepath.Join(w.dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	key, err := w.keyOf(name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.timeout)
+	defer cancel()
+	if err := w.store.Put(ctx, key, f, size, h.Sum(nil)); err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(path)
+}
+
+// keyOf executes the key template for the segment file name, "<id><ext>"
+func (w *Writer) keyOf(name string) (string, error) {
+	id, ext, _ := strings.Cut(name, ".")
+	seg := Segment{Host: w.host, ID: id, Ext: "." + ext}
+	if nanos, err := strconv.ParseInt(id, 36, 64); err == nil {
+		seg.Start = time.Unix(0, nanos).UTC()
+	}
+	var key bytes.Buffer
+	if err := w.key.Execute(&key, seg); err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+// prune deletes the stored segments older than the retention
+func (w *Writer) prune(ctx context.Context) error {
+	prefix, _, _ := strings.Cut(w.cfg.key, "{{")
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.timeout)
+	defer cancel()
+	objects, err := w.store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-w.cfg.retention)
+	for _, obj := range objects {
+		if obj.Modified.IsZero() || !obj.Modified.Before(cutoff) {
+			continue
+		}
+		if err := w.store.Delete(ctx, obj.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush closes the current segment and uploads the closed segments, waiting until they are
+// stored or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	err := w.roll()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.uploadAll(ctx)
+}
+
+// Close closes the current segment, uploads the closed segments and stops the writer.
+// Segments which could not be uploaded stay in the directory for the next run.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.roll()
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+	if uerr := w.uploadAll(context.Background()); err == nil {
+		err = uerr
+	}
+	return err
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("objstore", err)
+}
@@ -0,0 +1,79 @@
+package objstore
+
+import "time"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	key       string
+	maxSize   int64
+	maxAge    time.Duration
+	compress  bool
+	retention time.Duration
+	timeout   time.Duration
+	onError   func(error)
+}
+
+// WithKeyTemplate sets the text/template of the object keys, executed with a Segment.
+// Default is DefaultKeyTemplate. Retention only considers the keys starting with the text
+// before the first action of the template.
+func WithKeyTemplate(tmpl string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.key = tmpl
+	})
+}
+
+// WithSegment closes the segment and uploads it once it holds size bytes or is older than
+// age. Defaults are 64 MiB and 1 hour.
+func WithSegment(size int64, age time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxSize = size
+		cfg.maxAge = age
+	})
+}
+
+// WithCompress gzips the segments; Segment.Ext is ".log.gz" instead of ".log".
+func WithCompress() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.compress = true
+	})
+}
+
+// WithRetention deletes uploaded segments older than d from the store. Default is to keep
+// them, e.g. to leave retention to the lifecycle rules of the bucket.
+func WithRetention(d time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retention = d
+	})
+}
+
+// WithTimeout sets the timeout of a store call. Default is 1 minute.
+func WithTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.timeout = timeout
+	})
+}
+
+// WithErrorHandler is called with upload and retention errors. Default prints to stderr.
+// Segments which failed to upload stay in the directory and are retried.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		key:     DefaultKeyTemplate,
+		maxSize: 64 << 20,
+		maxAge:  time.Hour,
+		timeout: time.Minute,
+		onError: defaultErrorHandler,
+	}
+}
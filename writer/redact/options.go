@@ -0,0 +1,56 @@
+package redact
+
+import "regexp"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	fields   []string
+	allow    []string
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// WithFields replaces the values of the named fields, matched case-insensitively at any depth.
+// A dotted name such as user.ssn only matches the field at that path.
+func WithFields(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.fields = append(cfg.fields, fields...)
+	})
+}
+
+// WithPatterns masks the parts of string values matching any of the patterns,
+// e.g. Email, CreditCard, BearerToken or JWT.
+func WithPatterns(patterns ...*regexp.Regexp) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.patterns = append(cfg.patterns, patterns...)
+	})
+}
+
+// WithAllow keeps the values of the named fields untouched, e.g. a message field known to
+// never contain personal data. Dotted names match paths as in WithFields.
+func WithAllow(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.allow = append(cfg.allow, fields...)
+	})
+}
+
+// WithMask sets the replacement of redacted values. Default is DefaultMask.
+func WithMask(mask string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.mask = mask
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		fields: []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey"},
+		mask:   DefaultMask,
+	}
+}
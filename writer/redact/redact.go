@@ -0,0 +1,169 @@
+// Package redact removes personal data from zerolog JSON lines before they reach a writer.
+package redact
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/XiBao/logger/internal/jsonenc"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultMask replaces redacted values.
+const DefaultMask = "[REDACTED]"
+
+// Common patterns for WithPatterns.
+var (
+	Email       = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	CreditCard  = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	BearerToken = regexp.MustCompile(`(?i)bearer\s+[a-z0-9\-._~+/]+=*`)
+	JWT         = regexp.MustCompile(`eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+`)
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer redacts log lines and forwards them to the wrapped writer. Values of the redacted
+// fields are replaced whatever their type, at any depth or at a dotted path such as
+// user.ssn, and string and number values matching a pattern are masked, unless their field
+// is allowed. Install it as the output of the logger, e.g.
+// logger.SetLogger(zerolog.New(redact.New(os.Stdout, ...))), so every sink sees redacted events.
+type Writer struct {
+	next     io.Writer
+	fields   map[string]struct{}
+	allow    map[string]struct{}
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// New creates a Writer redacting lines written to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	w := &Writer{
+		next:     next,
+		fields:   make(map[string]struct{}, len(cfg.fields)),
+		allow:    make(map[string]struct{}, len(cfg.allow)),
+		patterns: cfg.patterns,
+		mask:     cfg.mask,
+	}
+	for _, f := range cfg.fields {
+		w.fields[strings.ToLower(f)] = struct{}{}
+	}
+	for _, f := range cfg.allow {
+		w.allow[strings.ToLower(f)] = struct{}{}
+	}
+	return w
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if _, err := w.next.Write(w.Redact(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.next.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	if _, err := lw.WriteLevel(level, w.Redact(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Redact returns the redacted copy of a JSON log line. Lines which are not JSON objects are
+// returned with patterns masked.
+func (w *Writer) Redact(line []byte) []byte {
+	trimmed := bytes.TrimRight(line, "\n")
+	if !gjson.ValidBytes(trimmed) {
+		return []byte(w.maskString(string(line)))
+	}
+	out := make([]byte, 0, len(line))
+	out = w.appendValue(out, "", gjson.ParseBytes(trimmed))
+	return append(out, line[len(trimmed):]...)
+}
+
+// appendValue appends the redacted value found at path, the lowercase dotted path of its field
+func (w *Writer) appendValue(out []byte, path string, value gjson.Result) []byte {
+	switch {
+	case value.IsObject():
+		out = append(out, '{')
+		first := true
+		value.ForEach(func(key, v gjson.Result) bool {
+			if !first {
+				out = append(out, ',')
+			}
+			first = false
+			out = append(out, key.Raw...)
+			out = append(out, ':')
+			name := strings.ToLower(key.String())
+			full := name
+			if path != "" {
+				full = path + "." + name
+			}
+			switch {
+			case w.matches(w.allow, name, full):
+				out = append(out, v.Raw...)
+			case w.matches(w.fields, name, full):
+				out = jsonenc.AppendString(out, w.mask)
+			default:
+				out = w.appendValue(out, full, v)
+			}
+			return true
+		})
+		return append(out, '}')
+	case value.IsArray():
+		out = append(out, '[')
+		for i, v := range value.Array() {
+			if i > 0 {
+				out = append(out, ',')
+			}
+			out = w.appendValue(out, path, v)
+		}
+		return append(out, ']')
+	case (value.Type == gjson.String || value.Type == gjson.Number) && len(w.patterns) > 0:
+		// a card number logged as an integer is masked like its string form
+		s := value.String()
+		if value.Type == gjson.Number {
+			s = value.Raw
+		}
+		masked := w.maskString(s)
+		if masked == s {
+			return append(out, value.Raw...)
+		}
+		return jsonenc.AppendString(out, masked)
+	}
+	return append(out, value.Raw...)
+}
+
+// matches reports whether the field named name at the dotted path full is in set
+func (w *Writer) matches(set map[string]struct{}, name, full string) bool {
+	if _, ok := set[name]; ok {
+		return true
+	}
+	_, ok := set[full]
+	return ok
+}
+
+func (w *Writer) maskString(s string) string {
+	for _, re := range w.patterns {
+		s = re.ReplaceAllLiteralString(s, w.mask)
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package redact
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []WriterOption
+		line string
+		want string
+	}{
+		{
+			name: "top level",
+			line: `{"user":"ada","password":"hunter2"}`,
+			want: `{"user":"ada","password":"[REDACTED]"}`,
+		},
+		{
+			name: "case insensitive",
+			line: `{"Authorization":"Basic abc"}`,
+			want: `{"Authorization":"[REDACTED]"}`,
+		},
+		{
+			name: "nested object",
+			line: `{"request":{"headers":{"authorization":"Bearer x","accept":"*/*"}}}`,
+			want: `{"request":{"headers":{"authorization":"[REDACTED]","accept":"*/*"}}}`,
+		},
+		{
+			name: "objects in array",
+			line: `{"users":[{"name":"a","token":"t1"},{"name":"b","token":"t2"}]}`,
+			want: `{"users":[{"name":"a","token":"[REDACTED]"},{"name":"b","token":"[REDACTED]"}]}`,
+		},
+		{
+			name: "non-string values",
+			opts: []WriterOption{WithFields("pin", "admin", "card", "meta")},
+			line: `{"pin":1234,"admin":true,"card":null,"meta":{"a":1},"secret":[1,2]}`,
+			want: `{"pin":"[REDACTED]","admin":"[REDACTED]","card":"[REDACTED]","meta":"[REDACTED]","secret":"[REDACTED]"}`,
+		},
+		{
+			name: "dotted path",
+			opts: []WriterOption{WithFields("user.ssn")},
+			line: `{"ssn":"keep","user":{"ssn":"123-45-6789","name":"ada"}}`,
+			want: `{"ssn":"keep","user":{"ssn":"[REDACTED]","name":"ada"}}`,
+		},
+		{
+			name: "dotted path through array",
+			opts: []WriterOption{WithFields("users.ssn")},
+			line: `{"users":[{"ssn":1},{"ssn":2}]}`,
+			want: `{"users":[{"ssn":"[REDACTED]"},{"ssn":"[REDACTED]"}]}`,
+		},
+		{
+			name: "allowed field",
+			opts: []WriterOption{WithAllow("token")},
+			line: `{"token":"t","password":"p"}`,
+			want: `{"token":"t","password":"[REDACTED]"}`,
+		},
+		{
+			name: "allowed path keeps patterns elsewhere",
+			opts: []WriterOption{WithPatterns(Email), WithAllow("audit.email")},
+			line: `{"audit":{"email":"ops@example.com"},"message":"sent to ada@example.com"}`,
+			want: `{"audit":{"email":"ops@example.com"},"message":"sent to [REDACTED]"}`,
+		},
+		{
+			name: "pattern in nested string",
+			opts: []WriterOption{WithPatterns(Email)},
+			line: `{"to":["ada@example.com","bob"]}`,
+			want: `{"to":["[REDACTED]","bob"]}`,
+		},
+		{
+			name: "pattern in number",
+			opts: []WriterOption{WithPatterns(CreditCard)},
+			line: `{"card":4111111111111111,"amount":42}`,
+			want: `{"card":"[REDACTED]","amount":42}`,
+		},
+		{
+			name: "mask",
+			opts: []WriterOption{WithMask("***")},
+			line: `{"secret":"s"}`,
+			want: `{"secret":"***"}`,
+		},
+		{
+			name: "not JSON",
+			opts: []WriterOption{WithPatterns(Email)},
+			line: `mail ada@example.com`,
+			want: `mail [REDACTED]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(nil, tt.opts...).Redact([]byte(tt.line + "\n"))
+			if string(got) != tt.want+"\n" {
+				t.Errorf("Redact(%s)\n got: %s want: %s", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterNestedFields(t *testing.T) {
+	var out bytes.Buffer
+	log := zerolog.New(New(&out, WithPatterns(Email)))
+	log.Info().
+		Dict("user", zerolog.Dict().Str("email", "ada@example.com").Str("password", "p").Int("id", 7)).
+		Msg("login")
+	want := `{"level":"info","user":{"email":"[REDACTED]","password":"[REDACTED]","id":7},"message":"login"}` + "\n"
+	if out.String() != want {
+		t.Errorf("got %s want %s", out.String(), want)
+	}
+}
@@ -0,0 +1,64 @@
+package rotate
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	maxSize        int
+	maxAge         int
+	maxBackups     int
+	localTime      bool
+	compress       bool
+	reopenOnSIGHUP bool
+}
+
+// WithMaxSize rotates the file once it reaches megabytes. Default is 100 megabytes.
+func WithMaxSize(megabytes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxSize = megabytes
+	})
+}
+
+// WithMaxAge removes rotated files older than days. Default is to keep them.
+func WithMaxAge(days int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxAge = days
+	})
+}
+
+// WithMaxBackups keeps at most count rotated files. Default is to keep all.
+func WithMaxBackups(count int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxBackups = count
+	})
+}
+
+// WithLocalTime uses the local time in rotated file names instead of UTC.
+func WithLocalTime() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.localTime = true
+	})
+}
+
+// WithCompress gzips rotated files.
+func WithCompress() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.compress = true
+	})
+}
+
+// WithReopenOnSIGHUP reopens the file when the process receives SIGHUP.
+func WithReopenOnSIGHUP() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.reopenOnSIGHUP = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{}
+}
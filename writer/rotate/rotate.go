@@ -0,0 +1,82 @@
+// Package rotate writes logs to a file rotated by size, pruned by age and backup count.
+package rotate
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer is a rotating log file. Rotated files are named after the file with the rotation
+// time inserted before the extension, and are optionally gzipped.
+type Writer struct {
+	file    *lumberjack.Logger
+	signals chan os.Signal
+	once    sync.Once
+}
+
+// New opens a rotating log file at filename, creating its directory if needed.
+func New(filename string, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0744); err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		file: &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    cfg.maxSize,
+			MaxAge:     cfg.maxAge,
+			MaxBackups: cfg.maxBackups,
+			LocalTime:  cfg.localTime,
+			Compress:   cfg.compress,
+		},
+	}
+	if cfg.reopenOnSIGHUP {
+		w.signals = make(chan os.Signal, 1)
+		signal.Notify(w.signals, syscall.SIGHUP)
+		go w.reopenOnSignal()
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+// Rotate closes the current file, moves it aside and opens a new one.
+func (w *Writer) Rotate() error {
+	return w.file.Rotate()
+}
+
+// Reopen closes the current file; the next write opens the file again. Use it after the
+// file was moved by an external tool such as logrotate.
+func (w *Writer) Reopen() error {
+	return w.file.Close()
+}
+
+// Close closes the file and stops listening for SIGHUP.
+func (w *Writer) Close() error {
+	w.once.Do(func() {
+		if w.signals != nil {
+			signal.Stop(w.signals)
+			close(w.signals)
+		}
+	})
+	return w.file.Close()
+}
+
+func (w *Writer) reopenOnSignal() {
+	for range w.signals {
+		_ = w.Reopen()
+	}
+}
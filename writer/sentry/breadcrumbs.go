@@ -0,0 +1,99 @@
+package sentry
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/XiBao/logger/common"
+	"github.com/getsentry/sentry-go"
+	"github.com/tidwall/gjson"
+)
+
+// breadcrumbScopes keeps a bounded ring buffer of breadcrumbs per scope, where a scope is
+// the value of a field shared by all events of one request (e.g. request_id added to the
+// logger stored in the request context). Least recently used scopes are evicted.
+type breadcrumbScopes struct {
+	mu        sync.Mutex
+	field     string
+	size      int
+	maxScopes int
+	scopes    map[string]*list.Element
+	lru       *list.List
+}
+
+type breadcrumbRing struct {
+	scope  string
+	crumbs []*sentry.Breadcrumb
+	next   int
+	full   bool
+}
+
+func newBreadcrumbScopes(field string, size int, maxScopes int) *breadcrumbScopes {
+	if size <= 0 {
+		size = 30
+	}
+	if maxScopes <= 0 {
+		maxScopes = 1000
+	}
+	return &breadcrumbScopes{
+		field:     field,
+		size:      size,
+		maxScopes: maxScopes,
+		scopes:    make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// scopeOf returns the scope value of the encoded log line
+func (s *breadcrumbScopes) scopeOf(data []byte) string {
+	return gjson.GetBytes(data, common.EscapePath(s.field)).String()
+}
+
+func (s *breadcrumbScopes) add(scope string, crumb *sentry.Breadcrumb) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.scopes[scope]
+	if ok {
+		s.lru.MoveToFront(el)
+	} else {
+		el = s.lru.PushFront(&breadcrumbRing{
+			scope:  scope,
+			crumbs: make([]*sentry.Breadcrumb, s.size),
+		})
+		s.scopes[scope] = el
+		if s.lru.Len() > s.maxScopes {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.scopes, oldest.Value.(*breadcrumbRing).scope)
+		}
+	}
+
+	ring := el.Value.(*breadcrumbRing)
+	ring.crumbs[ring.next] = crumb
+	ring.next = (ring.next + 1) % len(ring.crumbs)
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// take returns the breadcrumbs of scope in insertion order and forgets them
+func (s *breadcrumbScopes) take(scope string) []*sentry.Breadcrumb {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.scopes[scope]
+	if !ok {
+		return nil
+	}
+	s.lru.Remove(el)
+	delete(s.scopes, scope)
+
+	ring := el.Value.(*breadcrumbRing)
+	if !ring.full {
+		return ring.crumbs[:ring.next]
+	}
+	crumbs := make([]*sentry.Breadcrumb, 0, len(ring.crumbs))
+	crumbs = append(crumbs, ring.crumbs[ring.next:]...)
+	return append(crumbs, ring.crumbs[:ring.next]...)
+}
@@ -0,0 +1,88 @@
+package sentry
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// FieldTimesSeen is the extra reporting how often a deduplicated event occurred in the
+// previous window.
+const FieldTimesSeen = "times_seen"
+
+// deduplicator suppresses repeated events within a time window.
+type deduplicator struct {
+	mu        sync.Mutex
+	window    time.Duration
+	seen      map[uint64]*dedupEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type dedupEntry struct {
+	start time.Time
+	count int
+}
+
+func newDeduplicator(window time.Duration) *deduplicator {
+	return &deduplicator{
+		window: window,
+		seen:   make(map[uint64]*dedupEntry),
+		now:    time.Now,
+	}
+}
+
+// allow reports whether event should be captured. When a new window starts for an event
+// that repeated in the previous one, it gets the number of occurrences as times_seen extra.
+// Expired entries are swept once per window, except those with repeats still to report,
+// which are kept until their event occurs again.
+func (d *deduplicator) allow(event *sentry.Event) bool {
+	key := dedupKey(event)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastSweep) > d.window {
+		for k, e := range d.seen {
+			if k != key && e.count <= 1 && now.Sub(e.start) > d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	entry, ok := d.seen[key]
+	if ok && now.Sub(entry.start) <= d.window {
+		entry.count++
+		return false
+	}
+	if ok && entry.count > 1 {
+		if event.Extra == nil {
+			event.Extra = make(map[string]interface{})
+		}
+		event.Extra[FieldTimesSeen] = entry.count
+	}
+	d.seen[key] = &dedupEntry{start: now, count: 1}
+	return true
+}
+
+// dedupKey hashes message, exception types and fingerprint of event
+func dedupKey(event *sentry.Event) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(event.Message))
+	for _, ex := range event.Exception {
+		h.Write([]byte{0})
+		if ex.Type != "" {
+			h.Write([]byte(ex.Type))
+		} else {
+			h.Write([]byte(ex.Value))
+		}
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(event.Fingerprint, "\x00")))
+	return h.Sum64()
+}
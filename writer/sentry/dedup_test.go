@@ -0,0 +1,55 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestDeduplicatorSweepKeepsRepeats(t *testing.T) {
+	const window = time.Minute
+	now := time.Date(2024, 7, 22, 16, 0, 0, 0, time.UTC)
+	d := newDeduplicator(window)
+	d.now = func() time.Time { return now }
+
+	repeated := func() *sentry.Event { return &sentry.Event{Message: "repeated"} }
+	if !d.allow(repeated()) {
+		t.Fatal("first event suppressed")
+	}
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Second)
+		if d.allow(repeated()) {
+			t.Fatal("repeat within the window captured")
+		}
+	}
+
+	// another event sweeps the expired entries between the two windows
+	now = now.Add(2 * window)
+	if !d.allow(&sentry.Event{Message: "other"}) {
+		t.Fatal("other event suppressed")
+	}
+
+	now = now.Add(time.Second)
+	event := repeated()
+	if !d.allow(event) {
+		t.Fatal("event of the next window suppressed")
+	}
+	if got := event.Extra[FieldTimesSeen]; got != 3 {
+		t.Errorf("%s = %v, want 3", FieldTimesSeen, got)
+	}
+}
+
+func TestDeduplicatorSweepsSingles(t *testing.T) {
+	const window = time.Minute
+	now := time.Date(2024, 7, 22, 16, 0, 0, 0, time.UTC)
+	d := newDeduplicator(window)
+	d.now = func() time.Time { return now }
+
+	d.allow(&sentry.Event{Message: "once"})
+	now = now.Add(2 * window)
+	d.allow(&sentry.Event{Message: "other"})
+	if len(d.seen) != 1 {
+		t.Errorf("%d entries after the sweep, want 1", len(d.seen))
+	}
+}
@@ -0,0 +1,736 @@
+// Package sentry captures zerolog events to Sentry from the encoded log lines. It is a
+// module of its own, so the other writers do not depend on the Sentry SDK; it was the
+// package github.com/XiBao/logger/writer before.
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sentryutil"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	FieldTransaction = "sentry.tx"
+	FieldFingerprint = "fingerprint"
+
+	// conventional fields of DefaultRequestFields
+	FieldHTTPMethod = "http_method"
+	FieldURL        = "url"
+	FieldStatus     = "status"
+	FieldHeaders    = "headers"
+	FieldRemoteIP   = "remote_ip"
+)
+
+// RequestFields names the log fields mapped to the Sentry request and response contexts.
+// Empty names are not mapped, and values of an unexpected type stay in the event extras.
+type RequestFields struct {
+	Method   string
+	URL      string
+	Status   string
+	Headers  string
+	RemoteIP string
+}
+
+// DefaultRequestFields are the conventional fields, written by the HTTP middlewares.
+var DefaultRequestFields = RequestFields{
+	Method:   FieldHTTPMethod,
+	URL:      FieldURL,
+	Status:   FieldStatus,
+	Headers:  FieldHeaders,
+	RemoteIP: FieldRemoteIP,
+}
+
+// ErrFlushTimeout is returned when buffered events could not be delivered in time.
+var ErrFlushTimeout = errors.New("sentry: flush timed out")
+
+var _ = io.WriteCloser(new(Writer))
+
+type Writer struct {
+	levels          map[zerolog.Level]struct{}
+	withBreadcrumbs bool
+	fingerprint     []string
+	beforeSend      func(*sentry.Event) *sentry.Event
+	sampleRates     map[zerolog.Level]float64
+	hub             *sentry.Hub
+	flushTimeout    time.Duration
+	scopes          *breadcrumbScopes
+	transactions    bool
+	levelsMapping   map[zerolog.Level]sentry.Level
+	ignoreMessages  []*regexp.Regexp
+	ignoreErrors    []string
+	attachments     map[string]int
+	dedup           *deduplicator
+	tagFields       []string
+	userFields      common.UserFields
+	requestFields   RequestFields
+}
+
+// scopeOf returns the breadcrumb scope of the encoded log line, if scoped breadcrumbs are enabled
+func (w *Writer) scopeOf(data []byte) string {
+	if w.scopes == nil {
+		return ""
+	}
+	return w.scopes.scopeOf(data)
+}
+
+// addBreadcrumb adds event as a breadcrumb
+func (w *Writer) addBreadcrumb(event *sentry.Event, scope string) {
+	if !w.withBreadcrumbs {
+		return
+	}
+
+	// category is totally optional, but it's nice to have
+	var category string
+	if _, ok := event.Extra["category"]; ok {
+		if v, ok := event.Extra["category"].(string); ok {
+			category = v
+		}
+	}
+
+	crumb := &sentry.Breadcrumb{
+		Category:  category,
+		Message:   event.Message,
+		Level:     event.Level,
+		Data:      event.Extra,
+		Timestamp: event.Timestamp,
+	}
+	if scope != "" {
+		w.scopes.add(scope, crumb)
+		return
+	}
+	w.currentHub().AddBreadcrumb(crumb, nil)
+}
+
+func (w *Writer) Write(data []byte) (int, error) {
+	n := len(data)
+	lvl, err := w.parseLogLevel(data)
+	if err != nil {
+		return n, nil
+	}
+
+	event, ok := w.parseLogEvent(data)
+	if !ok {
+		return n, nil
+	}
+	event.Level = w.levelsMapping[lvl]
+	if w.ignored(event) {
+		return n, nil
+	}
+
+	if w.transactions {
+		if issue, ok := w.asTransaction(event, data); ok {
+			w.capture(event, "")
+			if _, enabled := w.levels[lvl]; issue != nil && (enabled || captureRequested(data)) && w.sampled(lvl) {
+				w.capture(issue, w.scopeOf(data))
+			}
+			return n, nil
+		}
+	}
+
+	if _, enabled := w.levels[lvl]; !enabled && !captureRequested(data) {
+		// if the level is not enabled, add event as a breadcrumb
+		w.addBreadcrumb(event, w.scopeOf(data))
+		return n, nil
+	}
+
+	if !w.sampled(lvl) {
+		return n, nil
+	}
+	w.capture(event, w.scopeOf(data))
+
+	return len(data), nil
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	n = len(p)
+
+	event, ok := w.parseLogEvent(p)
+	if !ok {
+		return
+	}
+	event.Level, ok = w.levelsMapping[level]
+	if !ok {
+		return
+	}
+	if w.ignored(event) {
+		return
+	}
+
+	if w.transactions {
+		if issue, ok := w.asTransaction(event, p); ok {
+			w.capture(event, "")
+			if _, enabled := w.levels[level]; issue != nil && (enabled || captureRequested(p)) && w.sampled(level) {
+				w.capture(issue, w.scopeOf(p))
+			}
+			return
+		}
+	}
+
+	if _, enabled := w.levels[level]; !enabled && !captureRequested(p) {
+		// if the level is not enabled, add event as a breadcrumb
+		w.addBreadcrumb(event, w.scopeOf(p))
+		return
+	}
+
+	if !w.sampled(level) {
+		return
+	}
+	w.capture(event, w.scopeOf(p))
+	return
+}
+
+// ignored reports whether event matches the ignored messages or errors
+func (w *Writer) ignored(event *sentry.Event) bool {
+	for _, re := range w.ignoreMessages {
+		if re.MatchString(event.Message) {
+			return true
+		}
+	}
+	for _, ignore := range w.ignoreErrors {
+		for _, ex := range event.Exception {
+			if ex.Type == ignore || strings.Contains(ex.Value, ignore) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// captureRequested reports whether the encoded line asks to be captured whatever its level,
+// see common.FieldCapture
+func captureRequested(data []byte) bool {
+	return gjson.GetBytes(data, common.EscapePath(common.FieldCapture)).Bool()
+}
+
+// sampled reports whether an event of the level passes the configured sample rate
+func (w *Writer) sampled(level zerolog.Level) bool {
+	rate, ok := w.sampleRates[level]
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// capture sends event to Sentry after passing it through the BeforeSend callback.
+// Scoped breadcrumbs collected for scope are attached to the event.
+func (w *Writer) capture(event *sentry.Event, scope string) {
+	if w.dedup != nil && event.Type != "transaction" && !w.dedup.allow(event) {
+		return
+	}
+	if scope != "" {
+		event.Breadcrumbs = append(event.Breadcrumbs, w.scopes.take(scope)...)
+	}
+	if w.beforeSend != nil {
+		if event = w.beforeSend(event); event == nil {
+			return
+		}
+	}
+	w.currentHub().CaptureEvent(event)
+}
+
+// currentHub returns the dedicated hub of the writer or the global one
+func (w *Writer) currentHub() *sentry.Hub {
+	if w.hub != nil {
+		return w.hub
+	}
+	return sentry.CurrentHub()
+}
+
+// Close flushes buffered events waiting at most the configured flush timeout.
+func (w *Writer) Close() error {
+	if !w.currentHub().Flush(w.flushTimeout) {
+		return ErrFlushTimeout
+	}
+	return nil
+}
+
+// Flush waits until buffered events are sent to Sentry or ctx is done.
+// Without a ctx deadline the configured flush timeout is used.
+//
+// sentry-go flushes with a timeout only. With a ctx deadline, Flush waits until the
+// deadline. Otherwise it returns when ctx is canceled, and the flush keeps running in the
+// background until the flush timeout at most.
+func (w *Writer) Flush(ctx context.Context) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if !w.currentHub().Flush(time.Until(deadline)) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return ErrFlushTimeout
+		}
+		return nil
+	}
+	done := make(chan bool, 1)
+	go func() {
+		done <- w.currentHub().Flush(w.flushTimeout)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ok := <-done:
+		if !ok {
+			return ErrFlushTimeout
+		}
+		return nil
+	}
+}
+
+// setRequestField maps the field key of the log line to the request or response context
+// of event, and reports whether it did
+func setRequestField(event *sentry.Event, fields RequestFields, key string, value gjson.Result) bool {
+	request := func() *sentry.Request {
+		if event.Request == nil {
+			event.Request = new(sentry.Request)
+		}
+		return event.Request
+	}
+	switch {
+	case key == "":
+		return false
+	case key == fields.Method && value.Type == gjson.String:
+		request().Method = value.String()
+	case key == fields.URL && value.Type == gjson.String:
+		request().URL = value.String()
+	case key == fields.RemoteIP && value.Type == gjson.String:
+		request().Env = map[string]string{"REMOTE_ADDR": value.String()}
+	case key == fields.Status && value.Type == gjson.Number:
+		event.Contexts["response"] = sentry.Context{"status_code": value.Int()}
+	case key == fields.Headers && value.IsObject():
+		headers := make(map[string]string)
+		value.ForEach(func(k, v gjson.Result) bool {
+			if v.IsArray() {
+				vals := v.Array()
+				strs := make([]string, 0, len(vals))
+				for _, vv := range vals {
+					strs = append(strs, vv.String())
+				}
+				headers[k.String()] = strings.Join(strs, ", ")
+			} else {
+				headers[k.String()] = v.String()
+			}
+			return true
+		})
+		request().Headers = headers
+	default:
+		return false
+	}
+	return true
+}
+
+// parses the log level from the encoded log
+func (w *Writer) parseLogLevel(data []byte) (zerolog.Level, error) {
+	lvlStr := gjson.GetBytes(data, zerolog.LevelFieldName).String()
+
+	return zerolog.ParseLevel(lvlStr)
+}
+
+// parseLogEvent converts the encoded log line into a Sentry event. Lines which are not JSON
+// objects are rejected.
+func (w *Writer) parseLogEvent(data []byte) (*sentry.Event, bool) {
+	const logger = "zerolog"
+
+	if !gjson.ValidBytes(data) {
+		return nil, false
+	}
+	line := gjson.ParseBytes(data)
+	if !line.IsObject() {
+		return nil, false
+	}
+
+	event := sentry.Event{
+		Timestamp: time.Now().UTC(),
+		Logger:    logger,
+		Contexts:  make(map[string]sentry.Context),
+	}
+
+	isStack := false
+	var errExept []sentry.Exception
+	var callerStack *sentry.Stacktrace
+	payload := make(sentry.Context)
+	fingerprint := make(map[string]string, len(w.fingerprint))
+
+	line.ForEach(func(key, value gjson.Result) bool {
+		for _, f := range w.fingerprint {
+			if f == key.String() {
+				fingerprint[f] = value.String()
+			}
+		}
+		if w.requestFields != (RequestFields{}) && setRequestField(&event, w.requestFields, key.String(), value) {
+			return true
+		}
+		switch key.String() {
+		// case zerolog.LevelFieldName, zerolog.TimestampFieldName:
+		case zerolog.MessageFieldName:
+			event.Message = value.String()
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				event.Timestamp = t.UTC()
+			}
+		case zerolog.ErrorFieldName:
+			errExept = append(errExept, sentry.Exception{
+				Value:      value.String(),
+				Stacktrace: sentryutil.Stacktrace(),
+			})
+		case zerolog.CallerFieldName:
+			callerStack = sentryutil.CallerStacktrace(value.String())
+			payload[key.String()] = value.String()
+		case FieldTransaction:
+			event.Transaction = value.String()
+		case common.FieldCapture:
+		case FieldFingerprint:
+			if value.IsArray() {
+				for _, v := range value.Array() {
+					event.Fingerprint = append(event.Fingerprint, v.String())
+				}
+			} else {
+				event.Fingerprint = append(event.Fingerprint, value.String())
+			}
+		case zerolog.ErrorStackFieldName:
+			var e sentryutil.ErrWithStackTrace
+			err := json.Unmarshal([]byte(value.Raw), &e)
+			if err != nil {
+				event.Level = sentry.LevelError
+				event.Exception = append(event.Exception, sentry.Exception{
+					Value:      err.Error(),
+					Stacktrace: sentry.ExtractStacktrace(err),
+				})
+				event.Message = fmt.Sprintf("Error unmarshal: %s", value)
+				break
+			}
+			event.Exception = append(event.Exception, sentry.Exception{
+				Value:      e.Err,
+				Stacktrace: e.Stacktrace,
+			})
+			isStack = true
+		default:
+			if threshold, ok := w.attachments[key.String()]; ok && len(value.Raw) > threshold {
+				event.Attachments = append(event.Attachments, attachment(key.String(), value))
+				break
+			}
+			payload[key.String()] = common.FieldValue(value)
+		}
+		return true
+	})
+
+	if len(payload) != 0 {
+		event.Contexts["payload"] = payload
+	}
+	if !isStack && len(errExept) > 0 {
+		if callerStack != nil {
+			// the caller is a better top frame than the stack of the writer
+			for i := range errExept {
+				errExept[i].Stacktrace = callerStack
+			}
+		}
+		event.Exception = errExept
+	}
+	if callerStack != nil && len(event.Exception) == 0 {
+		// message-only events get the caller as thread stack so they are grouped by location
+		event.Threads = append(event.Threads, sentry.Thread{
+			Stacktrace: callerStack,
+			Current:    true,
+		})
+	}
+	sentryutil.SetTags(&event, data, w.tagFields)
+	sentryutil.SetUser(&event, data, w.userFields)
+	// explicit fingerprint field wins over the configured fingerprint fields
+	if len(event.Fingerprint) == 0 {
+		for _, f := range w.fingerprint {
+			if v, ok := fingerprint[f]; ok {
+				event.Fingerprint = append(event.Fingerprint, v)
+			}
+		}
+	}
+
+	return &event, true
+}
+
+// attachment wraps a field value as a Sentry attachment
+func attachment(field string, value gjson.Result) *sentry.Attachment {
+	if value.Type == gjson.String {
+		return &sentry.Attachment{
+			Filename:    field + ".txt",
+			ContentType: "text/plain",
+			Payload:     []byte(value.String()),
+		}
+	}
+	return &sentry.Attachment{
+		Filename:    field + ".json",
+		ContentType: "application/json",
+		Payload:     []byte(value.Raw),
+	}
+}
+
+func bytesToStrUnsafe(data []byte) string {
+	// h := (*reflect.SliceHeader)(unsafe.Pointer(&data))
+	// return *(*string)(unsafe.Pointer(&reflect.StringHeader{Data: h.Data, Len: h.Len}))
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	levels         []zerolog.Level
+	breadcrumbs    bool
+	fingerprint    []string
+	beforeSend     func(*sentry.Event) *sentry.Event
+	sampleRates    map[zerolog.Level]float64
+	hub            *sentry.Hub
+	client         *sentry.ClientOptions
+	flushTimeout   time.Duration
+	scopeField     string
+	scopeSize      int
+	maxScopes      int
+	transactions   bool
+	levelsMapping  map[zerolog.Level]sentry.Level
+	ignoreMessages []*regexp.Regexp
+	ignoreErrors   []string
+	attachments    map[string]int
+	dedupWindow    time.Duration
+	tagFields      []string
+	userFields     common.UserFields
+	requestFields  RequestFields
+}
+
+// WithLevels configures zerolog levels that have to be sent to Sentry. Default levels are error, fatal, panic
+func WithLevels(levels ...zerolog.Level) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.levels = levels
+	})
+}
+
+// WithBreadcrumbs enables sentry client breadcrumbs.
+func WithBreadcrumbs() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.breadcrumbs = true
+	})
+}
+
+// WithFingerprint groups events into one Sentry issue by the values of the given fields
+// instead of the message text. A "fingerprint" field on the log line takes precedence.
+func WithFingerprint(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.fingerprint = fields
+	})
+}
+
+// WithBeforeSend sets a callback invoked before an event is captured. It may modify the event
+// or return nil to drop it.
+func WithBeforeSend(fn func(*sentry.Event) *sentry.Event) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.beforeSend = fn
+	})
+}
+
+// WithSampleRate sets the fraction (0.0 to 1.0) of events of level that are sent to Sentry.
+// Levels without a sample rate are always sent.
+func WithSampleRate(level zerolog.Level, rate float64) WriterOption {
+	return optionFunc(func(cfg *config) {
+		if cfg.sampleRates == nil {
+			cfg.sampleRates = make(map[zerolog.Level]float64)
+		}
+		cfg.sampleRates[level] = rate
+	})
+}
+
+// WithHub sends events through hub instead of the global sentry hub.
+func WithHub(hub *sentry.Hub) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.hub = hub
+	})
+}
+
+// WithClientOptions creates a dedicated client and hub for the writer, so writers with
+// different DSNs or configurations can coexist in one process.
+func WithClientOptions(opts sentry.ClientOptions) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.client = &opts
+	})
+}
+
+// WithFlushTimeout sets how long Close waits for buffered events to be sent. Default is 2 seconds.
+func WithFlushTimeout(timeout time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.flushTimeout = timeout
+	})
+}
+
+// WithScopedBreadcrumbs keeps breadcrumbs per request instead of on the hub. Events sharing
+// the same value of field (e.g. a request_id set on the logger stored in the request context)
+// form a scope holding its last size breadcrumbs, and only those are attached when an error
+// of that scope is captured. At most maxScopes scopes are kept, least recently used first out.
+// Implies WithBreadcrumbs.
+func WithScopedBreadcrumbs(field string, size int, maxScopes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.breadcrumbs = true
+		cfg.scopeField = field
+		cfg.scopeSize = size
+		cfg.maxScopes = maxScopes
+	})
+}
+
+// WithTransactions translates events carrying "span" and "duration" fields into Sentry
+// performance transactions instead of issues or breadcrumbs. Optional "trace_id" and
+// "span_id" fields attach the transaction to an existing trace. Spans of error events get the
+// internal_error status, and their error is also captured as an issue of the trace when
+// their level is captured.
+func WithTransactions() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.transactions = true
+	})
+}
+
+// WithIgnoreMessages drops events whose message matches any of the patterns.
+func WithIgnoreMessages(patterns ...*regexp.Regexp) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.ignoreMessages = append(cfg.ignoreMessages, patterns...)
+	})
+}
+
+// WithIgnoreErrors drops events with an error of one of the given types or containing
+// one of the given strings.
+func WithIgnoreErrors(errs ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.ignoreErrors = append(cfg.ignoreErrors, errs...)
+	})
+}
+
+// WithLevelMapping overrides the sentry level that zerolog levels are reported as.
+func WithLevelMapping(mapping map[zerolog.Level]sentry.Level) WriterOption {
+	return optionFunc(func(cfg *config) {
+		for zlvl, slvl := range mapping {
+			cfg.levelsMapping[zlvl] = slvl
+		}
+	})
+}
+
+// WithAttachmentField moves field out of the event payload into a Sentry attachment when its
+// encoded size exceeds threshold bytes, so large dumps are not truncated.
+func WithAttachmentField(field string, threshold int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		if cfg.attachments == nil {
+			cfg.attachments = make(map[string]int)
+		}
+		cfg.attachments[field] = threshold
+	})
+}
+
+// WithDeduplication suppresses events with the same message, exception types and fingerprint
+// for window after the first capture. The first event captured after the window carries the
+// number of occurrences during the previous window as "times_seen" extra.
+func WithDeduplication(window time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.dedupWindow = window
+	})
+}
+
+// WithTagFields sends the values of the given fields as indexed Sentry tags.
+func WithTagFields(fields ...string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.tagFields = append(cfg.tagFields, fields...)
+	})
+}
+
+// WithUserFields maps log fields to the Sentry user of the event.
+func WithUserFields(fields common.UserFields) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.userFields = fields
+	})
+}
+
+// WithRequestFields maps log fields to the Sentry request and response contexts of the
+// event, e.g. DefaultRequestFields. By default no field is mapped.
+func WithRequestFields(fields RequestFields) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.requestFields = fields
+	})
+}
+
+func New(opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	if len(opts) > 0 {
+		for _, opt := range opts {
+			opt.apply(&cfg)
+		}
+	}
+
+	hub := cfg.hub
+	if cfg.client != nil {
+		client, err := sentry.NewClient(*cfg.client)
+		if err != nil {
+			return nil, err
+		}
+		hub = sentry.NewHub(client, sentry.NewScope())
+	}
+
+	var scopes *breadcrumbScopes
+	if cfg.scopeField != "" {
+		scopes = newBreadcrumbScopes(cfg.scopeField, cfg.scopeSize, cfg.maxScopes)
+	}
+
+	var dedup *deduplicator
+	if cfg.dedupWindow > 0 {
+		dedup = newDeduplicator(cfg.dedupWindow)
+	}
+
+	levels := make(map[zerolog.Level]struct{}, len(cfg.levels))
+	for _, lvl := range cfg.levels {
+		levels[lvl] = struct{}{}
+	}
+
+	return &Writer{
+		levels:          levels,
+		withBreadcrumbs: cfg.breadcrumbs,
+		fingerprint:     cfg.fingerprint,
+		beforeSend:      cfg.beforeSend,
+		sampleRates:     cfg.sampleRates,
+		hub:             hub,
+		flushTimeout:    cfg.flushTimeout,
+		scopes:          scopes,
+		transactions:    cfg.transactions,
+		levelsMapping:   cfg.levelsMapping,
+		ignoreMessages:  cfg.ignoreMessages,
+		ignoreErrors:    cfg.ignoreErrors,
+		attachments:     cfg.attachments,
+		dedup:           dedup,
+		tagFields:       cfg.tagFields,
+		userFields:      cfg.userFields,
+		requestFields:   cfg.requestFields,
+	}, nil
+}
+
+func newDefaultConfig() config {
+	mapping := make(map[zerolog.Level]sentry.Level, len(sentryutil.LevelsMapping))
+	for zlvl, slvl := range sentryutil.LevelsMapping {
+		mapping[zlvl] = slvl
+	}
+	return config{
+		levelsMapping: mapping,
+		levels: []zerolog.Level{
+			zerolog.ErrorLevel,
+			zerolog.FatalLevel,
+			zerolog.PanicLevel,
+		},
+		flushTimeout: 2 * time.Second,
+	}
+}
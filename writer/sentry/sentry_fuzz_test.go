@@ -0,0 +1,93 @@
+package sentry
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// discardTransport drops the events captured by a client
+type discardTransport struct{}
+
+func (discardTransport) Flush(time.Duration) bool       { return true }
+func (discardTransport) Configure(sentry.ClientOptions) {}
+func (discardTransport) SendEvent(*sentry.Event)        {}
+
+// addLogLines adds the seed corpus of the fuzz targets: well-formed zerolog lines,
+// malformed JSON, non-UTF-8 bytes, huge fields and deeply nested values
+func addLogLines(f *testing.F) {
+	seeds := []string{
+		`{"level":"error","time":"2024-07-22T16:17:16Z","message":"failed"}`,
+		`{"level":"error","error":"boom","caller":"logger/log.go:42","stack":[{"func":"main","line":"12","source":"main.go"}]}`,
+		`{"level":"error","fingerprint":["a","b"],"sentry.tx":"GET /","headers":{"a":["1","2"]},"status":500}`,
+		`{"level":"error","headers":"not an object","stack":"not an object"}`,
+		`{"level":"warn","time":1721665036,"message":"unix time"}`,
+		`{"level":"error","message":"unterminated`,
+		`{"level":"error",}`,
+		`["level","error"]`,
+		`"error"`,
+		`null`,
+		``,
+		"{\"level\":\"error\",\"message\":\"\xff\xfe\xfd\"}",
+		"{\"level\":\"error\",\"\xc3\x28\":\"invalid key\"}",
+		`{"level":"error","big":"` + strings.Repeat("x", 1<<16) + `"}`,
+		`{"level":"error","deep":` + strings.Repeat(`{"a":`, 100) + `1` + strings.Repeat(`}`, 100) + `}`,
+		`{"level":"error","deep":` + strings.Repeat(`[`, 100) + strings.Repeat(`]`, 100) + `}`,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+}
+
+func FuzzParseLogEvent(f *testing.F) {
+	addLogLines(f)
+	w, err := New()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		event, ok := w.parseLogEvent(data)
+		if !ok {
+			return
+		}
+		if !gjson.ValidBytes(data) || !gjson.ParseBytes(data).IsObject() {
+			t.Fatalf("parsed %q which is not a JSON object", data)
+		}
+		if _, err := json.Marshal(event); err != nil {
+			t.Fatalf("event of %q cannot be encoded: %v", data, err)
+		}
+	})
+}
+
+func FuzzWrite(f *testing.F) {
+	addLogLines(f)
+	w, err := New(WithClientOptions(sentry.ClientOptions{Transport: discardTransport{}}), WithBreadcrumbs())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if n, err := w.Write(data); n != len(data) || err != nil {
+			t.Fatalf("Write = %d, %v, want %d, nil", n, err, len(data))
+		}
+	})
+}
+
+func FuzzWriteLevel(f *testing.F) {
+	addLogLines(f)
+	w, err := New(WithClientOptions(sentry.ClientOptions{Transport: discardTransport{}}), WithBreadcrumbs())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, level := range []zerolog.Level{zerolog.DebugLevel, zerolog.ErrorLevel, zerolog.NoLevel} {
+			if n, err := w.WriteLevel(level, data); n != len(data) || err != nil {
+				t.Fatalf("WriteLevel(%s) = %d, %v, want %d, nil", level, n, err, len(data))
+			}
+		}
+	})
+}
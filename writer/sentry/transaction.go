@@ -0,0 +1,75 @@
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// FieldSpan names the operation of an event translated into a Sentry transaction
+	FieldSpan = "span"
+	// FieldDuration is the duration of the span, encoded according to zerolog.DurationFieldUnit
+	FieldDuration = "duration"
+	// FieldTraceID and FieldSpanID link the transaction to an existing trace
+	FieldTraceID = "trace_id"
+	FieldSpanID  = "span_id"
+)
+
+// asTransaction turns event into a Sentry transaction if the log line carries span and
+// duration fields. The event timestamp is the end of the span. The status of the span is
+// internal_error for error levels and events with an error, whose exception, which a
+// transaction cannot carry, is returned as a separate issue attached to the same trace.
+func (w *Writer) asTransaction(event *sentry.Event, data []byte) (issue *sentry.Event, ok bool) {
+	res := gjson.GetManyBytes(data, FieldSpan, FieldDuration, FieldTraceID, FieldSpanID)
+	span, duration := res[0], res[1]
+	if !span.Exists() || duration.Type != gjson.Number {
+		return nil, false
+	}
+
+	traceID, spanID := res[2].String(), res[3].String()
+	if len(traceID) != 32 {
+		traceID = randomHex(16)
+	}
+	if len(spanID) != 16 {
+		spanID = randomHex(8)
+	}
+	status := "ok"
+	if event.Level == sentry.LevelError || event.Level == sentry.LevelFatal || len(event.Exception) > 0 {
+		status = "internal_error"
+	}
+	trace := sentry.Context{
+		"trace_id": traceID,
+		"span_id":  spanID,
+		"op":       span.String(),
+		"status":   status,
+	}
+
+	if len(event.Exception) > 0 {
+		copied := *event
+		copied.Contexts = make(map[string]sentry.Context, len(event.Contexts)+1)
+		for k, v := range event.Contexts {
+			copied.Contexts[k] = v
+		}
+		copied.Contexts["trace"] = sentry.Context{"trace_id": traceID, "span_id": spanID, "op": span.String()}
+		issue = &copied
+	}
+
+	event.Type = "transaction"
+	event.Transaction = span.String()
+	event.StartTime = event.Timestamp.Add(-time.Duration(duration.Float() * float64(zerolog.DurationFieldUnit)))
+	event.Exception = nil
+	event.Threads = nil
+	event.Contexts["trace"] = trace
+	return issue, true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
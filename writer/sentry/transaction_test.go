@@ -0,0 +1,42 @@
+package sentry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+func TestTransactionStatus(t *testing.T) {
+	var captured []*sentry.Event
+	w, err := New(WithTransactions(), WithBeforeSend(func(event *sentry.Event) *sentry.Event {
+		captured = append(captured, event)
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+
+	log.Info().Str(FieldSpan, "import").Dur(FieldDuration, time.Second).Msg("done")
+	log.Error().Str(FieldSpan, "import").Dur(FieldDuration, time.Second).Err(errors.New("boom")).Msg("failed")
+
+	if len(captured) != 3 {
+		t.Fatalf("captured %d events, want 2 transactions and 1 issue", len(captured))
+	}
+	if got := captured[0].Contexts["trace"]["status"]; got != "ok" {
+		t.Errorf("status of the successful span = %v, want ok", got)
+	}
+	tx, issue := captured[1], captured[2]
+	if got := tx.Contexts["trace"]["status"]; tx.Type != "transaction" || got != "internal_error" {
+		t.Errorf("failed span: type %q, status %v, want a transaction with internal_error", tx.Type, got)
+	}
+	if issue.Type == "transaction" || len(issue.Exception) == 0 || issue.Exception[0].Value != "boom" {
+		t.Fatalf("issue of the failed span lacks its exception: %+v", issue)
+	}
+	if issue.Contexts["trace"]["trace_id"] != tx.Contexts["trace"]["trace_id"] {
+		t.Error("issue not attached to the trace of the transaction")
+	}
+}
@@ -0,0 +1,100 @@
+package sqlsink
+
+import "time"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	dialect     Dialect
+	table       string
+	create      bool
+	batchLines  int
+	interval    time.Duration
+	maxBuffered int
+	retries     int
+	backoff     time.Duration
+	retention   time.Duration
+	onError     func(error)
+}
+
+// WithDialect sets the SQL dialect of the database. Default is Postgres.
+func WithDialect(d Dialect) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.dialect = d
+	})
+}
+
+// WithTable sets the table the events are inserted into, optionally qualified by its
+// schema. Default is "logs".
+func WithTable(table string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.table = table
+	})
+}
+
+// WithCreateTable creates the table and its time index when missing.
+func WithCreateTable() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.create = true
+	})
+}
+
+// WithBatch sets the number of lines that trigger an insert and the flush interval.
+// Defaults are 500 lines and 1 second.
+func WithBatch(lines int, interval time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.batchLines = lines
+		cfg.interval = interval
+	})
+}
+
+// WithMaxBuffered bounds the bytes held in memory. Default is 16 MiB.
+func WithMaxBuffered(bytes int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.maxBuffered = bytes
+	})
+}
+
+// WithRetry sets how often a failed insert is retried and the initial backoff, doubled
+// on every attempt. Defaults are 5 retries and 500 milliseconds.
+func WithRetry(retries int, backoff time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retries = retries
+		cfg.backoff = backoff
+	})
+}
+
+// WithRetention deletes the events older than d, checked when the writer starts and then
+// every hour, or every d if shorter. Default is to keep them.
+func WithRetention(d time.Duration) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.retention = d
+	})
+}
+
+// WithErrorHandler is called with insert and retention errors and dropped lines. Default
+// prints to stderr.
+func WithErrorHandler(fn func(error)) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.onError = fn
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		dialect:     Postgres,
+		table:       "logs",
+		batchLines:  500,
+		interval:    time.Second,
+		maxBuffered: 16 << 20,
+		retries:     5,
+		backoff:     500 * time.Millisecond,
+		onError:     defaultErrorHandler,
+	}
+}
@@ -0,0 +1,246 @@
+// Package sqlsink stores zerolog JSON lines in a SQL table, for deployments with a
+// database but no log stack.
+package sqlsink
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/XiBao/logger/internal/sinkerr"
+	"github.com/XiBao/logger/writer/internal/batch"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Dialect is the SQL dialect of the database.
+type Dialect int
+
+const (
+	// Postgres stores the fields as JSONB.
+	Postgres Dialect = iota
+	// SQLite stores the fields as JSON text.
+	SQLite
+)
+
+// maxRows keeps an insert below the 32766 parameters allowed by SQLite
+const maxRows = 8000
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var _ = io.WriteCloser(new(Writer))
+
+// Writer buffers log lines and inserts them in batches as rows of
+// (ts, level, message, fields), fields being the JSON object of the other fields of the
+// line. The database driver is the one of db; the package imports none.
+type Writer struct {
+	db      *sql.DB
+	cfg     config
+	batcher *batch.Batcher
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// New creates a Writer inserting into the table of db.
+func New(db *sql.DB, opts ...WriterOption) (*Writer, error) {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	cfg.onError = sinkerr.Counting("sqlsink", cfg.onError)
+	if db == nil {
+		return nil, fmt.Errorf("sqlsink: nil database")
+	}
+	if !tableName.MatchString(cfg.table) {
+		return nil, fmt.Errorf("sqlsink: invalid table name %q", cfg.table)
+	}
+	if cfg.batchLines <= 0 || cfg.batchLines > maxRows {
+		cfg.batchLines = maxRows
+	}
+
+	w := &Writer{db: db, cfg: cfg, done: make(chan struct{})}
+	if cfg.create {
+		if err := w.createTable(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	w.batcher = batch.New(batch.Config{
+		MaxLines:    cfg.batchLines,
+		Interval:    cfg.interval,
+		MaxBuffered: cfg.maxBuffered,
+		Retries:     cfg.retries,
+		Backoff:     cfg.backoff,
+		Send:        w.insert,
+		OnError:     cfg.onError,
+	})
+	if cfg.retention > 0 {
+		w.wg.Add(1)
+		go w.prune()
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.batcher.Add(bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
+
+// Flush inserts the buffered lines and waits until they are stored or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	return w.batcher.Flush(ctx)
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *Writer) Dropped() uint64 {
+	return w.batcher.Dropped()
+}
+
+// Close inserts the buffered lines and stops the writer. It does not close the database.
+func (w *Writer) Close() error {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	return w.batcher.Close()
+}
+
+func (w *Writer) createTable(ctx context.Context) error {
+	var ddl string
+	switch w.cfg.dialect {
+	case SQLite:
+		ddl = `CREATE TABLE IF NOT EXISTS %[1]s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts TIMESTAMP NOT NULL,
+	level TEXT NOT NULL,
+	message TEXT NOT NULL,
+	fields TEXT NOT NULL
+)`
+	default:
+		ddl = `CREATE TABLE IF NOT EXISTS %[1]s (
+	id BIGSERIAL PRIMARY KEY,
+	ts TIMESTAMPTZ NOT NULL,
+	level TEXT NOT NULL,
+	message TEXT NOT NULL,
+	fields JSONB NOT NULL
+)`
+	}
+	if _, err := w.db.ExecContext(ctx, fmt.Sprintf(ddl, w.cfg.table)); err != nil {
+		return fmt.Errorf("sqlsink: create table: %w", err)
+	}
+	schema, table := "", w.cfg.table
+	if i := strings.IndexByte(table, '.'); i >= 0 {
+		schema, table = table[:i+1], table[i+1:]
+	}
+	// Postgres creates the index in the schema of the table, SQLite wants it qualified
+	index, on := table+"_ts_idx", w.cfg.table
+	if w.cfg.dialect == SQLite {
+		index, on = schema+index, table
+	}
+	if _, err := w.db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (ts)", index, on)); err != nil {
+		return fmt.Errorf("sqlsink: create index: %w", err)
+	}
+	return nil
+}
+
+// insert stores one batch in a single statement
+func (w *Writer) insert(ctx context.Context, entries []batch.Entry) error {
+	var query strings.Builder
+	query.WriteString("INSERT INTO ")
+	query.WriteString(w.cfg.table)
+	query.WriteString(" (ts, level, message, fields) VALUES ")
+	args := make([]interface{}, 0, 4*len(entries))
+	for i, e := range entries {
+		if i > 0 {
+			query.WriteByte(',')
+		}
+		query.WriteByte('(')
+		for j := 0; j < 4; j++ {
+			if j > 0 {
+				query.WriteByte(',')
+			}
+			query.WriteString(w.placeholder(len(args) + j + 1))
+		}
+		query.WriteByte(')')
+		ts, level, message, fields := row(e)
+		args = append(args, ts, level, message, fields)
+	}
+	_, err := w.db.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+func (w *Writer) placeholder(n int) string {
+	if w.cfg.dialect == SQLite {
+		return "?"
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// row splits a line into the columns of its row
+func row(e batch.Entry) (ts time.Time, level string, message string, fields string) {
+	ts = e.Time
+	level = zerolog.NoLevel.String()
+	if !gjson.ValidBytes(e.Line) {
+		return ts.UTC(), level, string(e.Line), "{}"
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	gjson.ParseBytes(e.Line).ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.LevelFieldName:
+			level = value.String()
+		case zerolog.MessageFieldName:
+			message = value.String()
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				ts = t
+				break
+			}
+			fallthrough
+		default:
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(key.Raw)
+			buf.WriteByte(':')
+			buf.WriteString(value.Raw)
+		}
+		return true
+	})
+	buf.WriteByte('}')
+	return ts.UTC(), level, message, buf.String()
+}
+
+// prune deletes the expired events until the writer is closed
+func (w *Writer) prune() {
+	defer w.wg.Done()
+	every := time.Hour
+	if w.cfg.retention < every {
+		every = w.cfg.retention
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		query := fmt.Sprintf("DELETE FROM %s WHERE ts < %s", w.cfg.table, w.placeholder(1))
+		cutoff := time.Now().Add(-w.cfg.retention).UTC()
+		if _, err := w.db.Exec(query, cutoff); err != nil {
+			w.cfg.onError(fmt.Errorf("sqlsink: retention: %w", err))
+		}
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func defaultErrorHandler(err error) {
+	sinkerr.Handle("sqlsink", err)
+}
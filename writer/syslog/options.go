@@ -0,0 +1,79 @@
+package syslog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	facility      Facility
+	hostname      string
+	appName       string
+	procID        string
+	msgID         string
+	sdID          string
+	octetCounting bool
+}
+
+// WithFacility sets the facility of the messages. Default is User.
+func WithFacility(facility Facility) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.facility = facility
+	})
+}
+
+// WithHostname sets the HOSTNAME header. Default is the host name of the machine.
+func WithHostname(hostname string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.hostname = hostname
+	})
+}
+
+// WithAppName sets the APP-NAME header. Default is the name of the executable.
+func WithAppName(name string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.appName = name
+	})
+}
+
+// WithMsgID sets the MSGID header.
+func WithMsgID(id string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.msgID = id
+	})
+}
+
+// WithStructuredDataID sets the SD-ID holding the fields. Default is "fields@32473", using
+// the example enterprise number of RFC 5424.
+func WithStructuredDataID(id string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.sdID = id
+	})
+}
+
+// WithOctetCounting frames messages with their length (RFC 6587) instead of a trailing
+// newline, as expected by syslog over TCP.
+func WithOctetCounting() WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.octetCounting = true
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		facility: User,
+		hostname: defaultHostname(),
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+		sdID:     "fields@32473",
+	}
+}
@@ -0,0 +1,239 @@
+// Package syslog formats zerolog JSON lines as RFC 5424 syslog messages.
+package syslog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/XiBao/logger/common"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+// Facility is a syslog facility.
+type Facility int
+
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	Lpr
+	News
+	Uucp
+	Cron
+	AuthPriv
+	Ftp
+	Local0 Facility = iota + 4
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Severity is a syslog severity.
+type Severity int
+
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// severities maps zerolog levels to syslog severities
+var severities = map[zerolog.Level]Severity{
+	zerolog.TraceLevel: Debug,
+	zerolog.DebugLevel: Debug,
+	zerolog.InfoLevel:  Informational,
+	zerolog.WarnLevel:  Warning,
+	zerolog.ErrorLevel: Error,
+	zerolog.FatalLevel: Critical,
+	zerolog.PanicLevel: Emergency,
+	zerolog.NoLevel:    Notice,
+}
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Writer formats each line as an RFC 5424 message and writes it to the wrapped writer,
+// e.g. a writer/net Writer connected to rsyslog or syslog-ng. The message field becomes the
+// MSG part and the other fields the structured data.
+type Writer struct {
+	next io.Writer
+	cfg  config
+	mu   sync.Mutex
+	buf  []byte
+}
+
+// New creates a Writer writing syslog messages to next.
+func New(next io.Writer, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Writer{next: next, cfg: cfg}
+}
+
+// Write parses the level from the encoded line.
+func (w *Writer) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if lvl := gjson.GetBytes(p, zerolog.LevelFieldName); lvl.Exists() {
+		if parsed, err := zerolog.ParseLevel(lvl.String()); err == nil {
+			level = parsed
+		}
+	}
+	return w.WriteLevel(level, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.Format(w.buf[:0], level, p)
+	if _, err := w.next.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.next.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Format appends the syslog message of the encoded line to dst, framed as configured.
+func (w *Writer) Format(dst []byte, level zerolog.Level, p []byte) []byte {
+	severity, ok := severities[level]
+	if !ok {
+		severity = Notice
+	}
+
+	msg := make([]byte, 0, len(p)+64)
+	msg = append(msg, '<')
+	msg = strconv.AppendInt(msg, int64(w.cfg.facility)*8+int64(severity), 10)
+	msg = append(msg, ">1 "...)
+
+	ts := time.Now()
+	var message string
+	var sd []byte
+	gjson.ParseBytes(bytes.TrimRight(p, "\n")).ForEach(func(key, value gjson.Result) bool {
+		switch key.String() {
+		case zerolog.LevelFieldName:
+		case zerolog.MessageFieldName:
+			message = value.String()
+		case zerolog.TimestampFieldName:
+			if t, ok := common.ParseTime(value); ok {
+				ts = t
+			}
+		default:
+			name := paramName(key.String())
+			if name == "" {
+				break
+			}
+			sd = append(sd, ' ')
+			sd = append(sd, name...)
+			sd = append(sd, '=', '"')
+			if value.Type == gjson.String {
+				sd = appendParamValue(sd, value.String())
+			} else {
+				sd = appendParamValue(sd, value.Raw)
+			}
+			sd = append(sd, '"')
+		}
+		return true
+	})
+
+	msg = ts.UTC().AppendFormat(msg, "2006-01-02T15:04:05.000000Z07:00")
+	msg = append(msg, ' ')
+	msg = append(msg, header(w.cfg.hostname, 255)...)
+	msg = append(msg, ' ')
+	msg = append(msg, header(w.cfg.appName, 48)...)
+	msg = append(msg, ' ')
+	msg = append(msg, header(w.cfg.procID, 128)...)
+	msg = append(msg, ' ')
+	msg = append(msg, header(w.cfg.msgID, 32)...)
+	msg = append(msg, ' ')
+	if len(sd) == 0 {
+		msg = append(msg, '-')
+	} else {
+		msg = append(msg, '[')
+		msg = append(msg, w.cfg.sdID...)
+		msg = append(msg, sd...)
+		msg = append(msg, ']')
+	}
+	if message != "" {
+		msg = append(msg, ' ')
+		msg = append(msg, message...)
+	}
+
+	if w.cfg.octetCounting {
+		dst = strconv.AppendInt(dst, int64(len(msg)), 10)
+		dst = append(dst, ' ')
+		return append(dst, msg...)
+	}
+	dst = append(dst, msg...)
+	return append(dst, '\n')
+}
+
+// header returns a header field as printable ASCII without spaces, or the nil value "-"
+func header(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > max {
+		b = b[:max]
+	}
+	return string(b)
+}
+
+// paramName sanitizes a field name into an SD-NAME
+func paramName(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < 32; i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			continue
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// appendParamValue escapes '"', '\' and ']' in a PARAM-VALUE
+func appendParamValue(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			dst = append(dst, '\\')
+		}
+		dst = append(dst, s[i])
+	}
+	return dst
+}
+
+func defaultHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
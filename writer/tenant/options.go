@@ -0,0 +1,48 @@
+package tenant
+
+import "io"
+
+type WriterOption interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) { fn(c) }
+
+type config struct {
+	field    string
+	maxOpen  int
+	fallback io.Writer
+}
+
+// WithField sets the field holding the tenant. Default is tenant_id.
+func WithField(name string) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.field = name
+	})
+}
+
+// WithMaxOpen keeps at most n sinks open, closing the least recently used one when a new
+// tenant arrives. Default is 64.
+func WithMaxOpen(n int) WriterOption {
+	return optionFunc(func(cfg *config) {
+		if n > 0 {
+			cfg.maxOpen = n
+		}
+	})
+}
+
+// WithFallback writes lines without a tenant to w. Default is to drop them.
+func WithFallback(w io.Writer) WriterOption {
+	return optionFunc(func(cfg *config) {
+		cfg.fallback = w
+	})
+}
+
+func newDefaultConfig() config {
+	return config{
+		field:   "tenant_id",
+		maxOpen: 64,
+	}
+}
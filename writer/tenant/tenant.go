@@ -0,0 +1,163 @@
+// Package tenant routes log lines to per-tenant writers.
+package tenant
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+)
+
+var _ = zerolog.LevelWriter(new(Writer))
+
+// Factory opens the sink of a tenant.
+type Factory func(tenant string) (io.Writer, error)
+
+type sink struct {
+	tenant string
+	w      io.Writer
+}
+
+// Writer reads the tenant field of each line and writes the line to the sink of that
+// tenant. Sinks are opened on the first line of their tenant and the least recently used
+// ones are closed, if they are io.Closer, once more than the maximum are open; a later line
+// of the tenant opens its sink again. Writes are serialized.
+type Writer struct {
+	open     Factory
+	field    string
+	maxOpen  int
+	fallback io.Writer
+
+	mu     sync.Mutex
+	sinks  map[string]*list.Element
+	lru    *list.List
+	closed bool
+}
+
+// New creates a Writer opening sinks with open.
+func New(open Factory, opts ...WriterOption) *Writer {
+	cfg := newDefaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	return &Writer{
+		open:     open,
+		field:    cfg.field,
+		maxOpen:  cfg.maxOpen,
+		fallback: cfg.fallback,
+		sinks:    make(map[string]*list.Element, cfg.maxOpen),
+		lru:      list.New(),
+	}
+}
+
+// Files returns a Factory appending the lines of each tenant to dir/<tenant>.log. Tenants
+// which are not valid file names are rejected.
+func Files(dir string) Factory {
+	return func(tenant string) (io.Writer, error) {
+		if tenant == "." || tenant == ".." || strings.ContainsAny(tenant, `/\`) {
+			return nil, fmt.Errorf("tenant: invalid tenant %q", tenant)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		return os.OpenFile(filepath.Join(dir, tenant+".log"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// implements zerolog.LevelWriter
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	tenant := gjson.GetBytes(p, w.field).String()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("tenant: writer closed")
+	}
+	out := w.fallback
+	if tenant != "" {
+		var err error
+		if out, err = w.sink(tenant); err != nil {
+			return 0, err
+		}
+	}
+	if out == nil {
+		return len(p), nil
+	}
+	var err error
+	if lw, ok := out.(zerolog.LevelWriter); ok {
+		_, err = lw.WriteLevel(level, p)
+	} else {
+		_, err = out.Write(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sink returns the open sink of tenant, opening it and evicting the least recently used
+// sink when needed
+func (w *Writer) sink(tenant string) (io.Writer, error) {
+	if e, ok := w.sinks[tenant]; ok {
+		w.lru.MoveToFront(e)
+		return e.Value.(*sink).w, nil
+	}
+	out, err := w.open(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("tenant: open %q: %w", tenant, err)
+	}
+	for w.lru.Len() >= w.maxOpen {
+		w.evict(w.lru.Back())
+	}
+	w.sinks[tenant] = w.lru.PushFront(&sink{tenant: tenant, w: out})
+	return out, nil
+}
+
+func (w *Writer) evict(e *list.Element) error {
+	s := w.lru.Remove(e).(*sink)
+	delete(w.sinks, s.tenant)
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Len returns the number of open sinks.
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lru.Len()
+}
+
+// Close closes the open sinks and the fallback writer if it is an io.Closer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	var errs []error
+	for w.lru.Len() > 0 {
+		if err := w.evict(w.lru.Back()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c, ok := w.fallback.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}